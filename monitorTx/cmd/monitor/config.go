@@ -0,0 +1,864 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"gopkg.in/yaml.v3"
+
+	"github.com/dzshubin/HackInEthereum/monitorTx/monitor"
+)
+
+// Output formats for matched transactions.
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+	OutputCSV  = "csv"
+)
+
+// Actions taken on a matched transaction.
+const (
+	ActionLog      = "log"
+	ActionWebhook  = "webhook"
+	ActionTelegram = "telegram"
+	ActionSend     = "send"
+	ActionKafka    = "kafka"
+	ActionRelay    = "relay"
+)
+
+// Log formats.
+const (
+	LogText = "text"
+	LogJSON = "json"
+)
+
+// stringList collects the values of a repeatable, comma-separated flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// UnmarshalYAML accepts a single, possibly comma-separated, value as well
+// as a list.
+func (l *stringList) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*l = nil
+		return l.Set(n.Value)
+	}
+	var list []string
+	if err := n.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+func (l *stringList) Set(s string) error {
+	for _, a := range strings.Split(s, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			*l = append(*l, a)
+		}
+	}
+	return nil
+}
+
+// repeatedList collects the values of a repeatable flag as given, commas
+// included.
+type repeatedList []string
+
+func (l *repeatedList) String() string {
+	return strings.Join(*l, "; ")
+}
+
+// UnmarshalYAML accepts a single value as well as a list.
+func (l *repeatedList) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*l = repeatedList{n.Value}
+		return nil
+	}
+	var list []string
+	if err := n.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+func (l *repeatedList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+// Config holds every setting of the monitor. It is filled from the command
+// line and optionally a YAML file, whose keys are the flag names.
+type Config struct {
+	Endpoints stringList   `yaml:"endpoint"`
+	Policy    string       `yaml:"endpoint-policy"`
+	Provider  string       `yaml:"provider"`
+	Headers   repeatedList `yaml:"header"`
+	ChainID   uint64       `yaml:"chain-id"`
+	Addresses stringList   `yaml:"address"`
+	To        stringList   `yaml:"to"`
+	Match     string       `yaml:"match"`
+
+	AddressPrefixes stringList `yaml:"address-prefix"`
+	AddressStdin    bool       `yaml:"address-stdin"`
+
+	StrictChecksum bool `yaml:"strict-checksum"`
+
+	// filters
+	MinValue     string     `yaml:"min-value"`
+	MaxValue     string     `yaml:"max-value"`
+	MinGasPrice  string     `yaml:"min-gas-price"`
+	Methods      stringList `yaml:"method"`
+	Nonce        string     `yaml:"nonce"`
+	NonceMin     string     `yaml:"nonce-min"`
+	NonceMax     string     `yaml:"nonce-max"`
+	ToAllow      stringList `yaml:"to-allow"`
+	ToDeny       stringList `yaml:"to-deny"`
+	AccessList   stringList `yaml:"accesslist-contains"`
+	MatchTxTypes stringList `yaml:"match-tx-type"`
+	Creations    bool       `yaml:"creations"`
+	CreationOnly bool       `yaml:"creation-only"`
+	NonZeroData  bool       `yaml:"non-zero-data"`
+	ZeroDataOnly bool       `yaml:"zero-data-only"`
+	Filter       string     `yaml:"filter"`
+	ABI          string     `yaml:"abi"`
+	Contract     string     `yaml:"abi-contract"`
+	FromBlock    string     `yaml:"from-block"`
+	Replay       string     `yaml:"replay"`
+
+	// output
+	Debug       bool   `yaml:"debug"`
+	LogLevel    string `yaml:"log-level"`
+	LogFormat   string `yaml:"log-format"`
+	Quiet       bool   `yaml:"quiet"`
+	Output      string `yaml:"output"`
+	OutputFile  string `yaml:"output-file"`
+	RotateSize  int    `yaml:"rotate-size"`
+	RotateCount int    `yaml:"rotate-count"`
+	DataLimit   int    `yaml:"data-limit"`
+	Raw         bool   `yaml:"raw"`
+	Labels      string `yaml:"labels"`
+	MetricsAddr string `yaml:"metrics-addr"`
+
+	PriceSource   string        `yaml:"price-source"`
+	PriceInterval time.Duration `yaml:"price-interval"`
+
+	// fetching
+	DrainTimeout     time.Duration `yaml:"drain-timeout"`
+	Duration         time.Duration `yaml:"duration"`
+	Concurrency      int           `yaml:"concurrency"`
+	FetchTimeout     time.Duration `yaml:"fetch-timeout"`
+	BatchWindow      time.Duration `yaml:"batch-window"`
+	PollInterval     time.Duration `yaml:"poll-interval"`
+	Heartbeat        time.Duration `yaml:"heartbeat"`
+	ResubInterval    time.Duration `yaml:"resub-interval"`
+	ResubIdle        time.Duration `yaml:"resub-idle"`
+	LatencyInterval  time.Duration `yaml:"latency-interval"`
+	ErrorInterval    time.Duration `yaml:"error-interval"`
+	MaxBackoff       time.Duration `yaml:"max-backoff"`
+	StartAttempts    int           `yaml:"start-attempts"`
+	DedupSize        int           `yaml:"dedup-size"`
+	SubBuffer        int           `yaml:"subch-buffer"`
+	TxBuffer         int           `yaml:"tx-buffer"`
+	RPS              float64       `yaml:"rps"`
+	NoFetch          bool          `yaml:"no-fetch"`
+	WatchHeads       bool          `yaml:"watch-heads"`
+	NonceGaps        bool          `yaml:"nonce-gaps"`
+	WatchBalance     bool          `yaml:"watch-balance"`
+	BalanceThreshold string        `yaml:"balance-threshold"`
+	Check            bool          `yaml:"check"`
+	Once             bool          `yaml:"once"`
+
+	// handlers
+	Actions         stringList    `yaml:"action"`
+	Confirmations   uint64        `yaml:"confirmations"`
+	Coalesce        time.Duration `yaml:"coalesce"`
+	ConfirmTimeout  time.Duration `yaml:"confirm-timeout"`
+	SeenDB          string        `yaml:"seen-db"`
+	SeenLimit       int           `yaml:"seen-limit"`
+	SQLite          string        `yaml:"sqlite"`
+	Socket          string        `yaml:"socket"`
+	Webhook         string        `yaml:"webhook"`
+	WebhookTimeout  time.Duration `yaml:"webhook-timeout"`
+	WebhookRetries  int           `yaml:"webhook-retries"`
+	WebhookHeaders  repeatedList  `yaml:"webhook-header"`
+	WebhookTemplate string        `yaml:"webhook-template"`
+	WebhookSecret   string        `yaml:"webhook-secret"`
+	RelayEndpoint   string        `yaml:"relay-endpoint"`
+	RelayHeaders    repeatedList  `yaml:"relay-header"`
+	KafkaBrokers    stringList    `yaml:"kafka-brokers"`
+	KafkaTopic      string        `yaml:"kafka-topic"`
+	TelegramToken   string        `yaml:"telegram-token"`
+	TelegramChatID  string        `yaml:"telegram-chat-id"`
+	TelegramTimeout time.Duration `yaml:"telegram-timeout"`
+
+	// the response tx of Process
+	KeyFile      string `yaml:"keyfile"`
+	Keystore     string `yaml:"keystore"`
+	PasswordFile string `yaml:"keystore-password-file"`
+	TxType       string `yaml:"tx-type"`
+	ActionTo     string `yaml:"action-to"`
+	ActionValue  string `yaml:"action-value"`
+	ActionData   string `yaml:"action-data"`
+	DryRun       bool   `yaml:"dry-run"`
+	PrintRaw     bool   `yaml:"print-raw"`
+	Simulate     bool   `yaml:"simulate"`
+	MaxGasPrice  string `yaml:"max-gas-price"`
+	GasPadding   int    `yaml:"gas-padding"`
+
+	ReplaceAfter time.Duration `yaml:"replace-after"`
+	MaxBumps     int           `yaml:"max-bumps"`
+	SendRetries  int           `yaml:"send-retries"`
+	SendBackoff  time.Duration `yaml:"send-backoff"`
+
+	// parsed by Validate
+	pool             *monitor.EndpointPool
+	senders          []common.Address
+	recipients       []common.Address
+	prefixes         []monitor.AddressPrefix
+	balanceThreshold *big.Int
+	selectors        map[[monitor.SelectorLength]byte]struct{}
+	contract         *common.Address
+	toAllow          []common.Address
+	toDeny           []common.Address
+	accessList       []common.Address
+	txTypes          map[uint8]struct{}
+	nonceMin         *uint64
+	nonceMax         *uint64
+	minWei           *big.Int
+	maxWei           *big.Int
+	minGasPrice      *big.Int
+	filter           *monitor.FilterExpr
+	maxGasPrice      *big.Int
+	key              *ecdsa.PrivateKey
+	actionTo         common.Address
+	actionValue      *big.Int
+	actionData       []byte
+	webhookHeader    http.Header
+	relayHeader      http.Header
+	webhookTemplate  *template.Template
+}
+
+const defaultEndpoint = "wss://mainnet.infura.io/ws"
+
+// flagAliases maps alternative flag names to the setting they set.
+var flagAliases = map[string]string{"ws": "endpoint"}
+
+// RegisterFlags binds every setting to a flag of fs.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.Var(&c.Endpoints, "endpoint", "Nodes to watch, the first is used and the others take over, repeated or comma-separated: a ws(s) url or IPC socket path subscribes, an http(s) url polls (default "+defaultEndpoint+")")
+	fs.Var(&c.Endpoints, "ws", "Alias of -endpoint")
+	fs.StringVar(&c.Policy, "endpoint-policy", monitor.PolicyFailover, "Use of several endpoints: failover, or round-robin to also spread tx fetches")
+	fs.StringVar(&c.Provider, "provider", monitor.ProviderAuto, "Pending tx subscription: alchemy takes the txs of the watched addresses whole from alchemy_pendingTransactions, generic fetches every announced one, auto picks alchemy for alchemy.com endpoints")
+	fs.Var(&c.Headers, "header", `Header sent to the http and ws endpoints, such as "Authorization: Bearer X", repeated for several`)
+	fs.Uint64Var(&c.ChainID, "chain-id", 0, "Chain ID used to sign and recover senders instead of the node's, 0 detects it")
+	fs.Var(&c.Addresses, "address", "Your designated addresses, comma-separated or repeated")
+	fs.Var(&c.To, "to", "Recipient addresses to watch, defaults to -address")
+	fs.BoolVar(&c.AddressStdin, "address-stdin", false, "Also read designated addresses from stdin, one per line, such as the output of another tool")
+	fs.Var(&c.AddressPrefixes, "address-prefix", "Also watch every address starting with these hex digits, such as 0xdead, on the side picked by -match")
+	fs.StringVar(&c.Match, "match", monitor.MatchFrom, "Which side of a tx to match: from, to or either")
+	fs.BoolVar(&c.StrictChecksum, "strict-checksum", false, "Reject, rather than warn about, addresses failing their EIP-55 checksum")
+
+	fs.StringVar(&c.MinValue, "min-value", "", "Minimum tx value in ETH, e.g. 0.5")
+	fs.StringVar(&c.MaxValue, "max-value", "", "Maximum tx value in ETH, unset means unbounded")
+	fs.StringVar(&c.MinGasPrice, "min-gas-price", "", "Only match txs offering at least this many gwei per gas: the gas price of legacy txs, the tip of EIP-1559 txs")
+	fs.Var(&c.Methods, "method", "Only match calls to these 4-byte method selectors, comma-separated or repeated")
+	fs.StringVar(&c.Nonce, "nonce", "", "Only match txs with this nonce")
+	fs.StringVar(&c.NonceMin, "nonce-min", "", "Only match txs with at least this nonce")
+	fs.StringVar(&c.NonceMax, "nonce-max", "", "Only match txs with at most this nonce")
+	fs.Var(&c.ToAllow, "to-allow", "Only match txs sent to these addresses, comma-separated or repeated")
+	fs.Var(&c.ToDeny, "to-deny", "Never match txs sent to these addresses, wins over -to-allow")
+	fs.Var(&c.AccessList, "accesslist-contains", "Only match txs whose access list includes one of these addresses, comma-separated or repeated")
+	fs.Var(&c.MatchTxTypes, "match-tx-type", "Only match txs of these types, comma-separated or repeated: legacy, accesslist, dynamic, blob or setcode")
+	fs.BoolVar(&c.Creations, "creations", true, "Match contract creations, which have no recipient, -creations=false excludes them")
+	fs.BoolVar(&c.CreationOnly, "creation-only", false, "Only match contract creations")
+	fs.BoolVar(&c.NonZeroData, "non-zero-data", false, "Only match txs with input data, such as contract calls")
+	fs.BoolVar(&c.ZeroDataOnly, "zero-data-only", false, "Only match txs without input data, such as plain transfers")
+	fs.StringVar(&c.Filter, "filter", "", "Only match txs satisfying this expression of from, to, value, gas, gasPrice, nonce and selector, e.g. 'value > 1e18 && selector == 0xa9059cbb'")
+	fs.StringVar(&c.ABI, "abi", "", "JSON ABI file used to decode the input of matched txs")
+	fs.StringVar(&c.Contract, "abi-contract", "", "Only decode txs sent to this contract with -abi")
+	fs.StringVar(&c.FromBlock, "from-block", "", "Scan blocks from this number, or latest-K for the last K blocks, before watching")
+	fs.StringVar(&c.Replay, "replay", "", "Run the txs of the hashes in this file, one per line, through the filters and actions instead of watching")
+
+	fs.BoolVar(&c.Debug, "debug", false, "Log debug messages, same as -log-level debug")
+	fs.StringVar(&c.LogLevel, "log-level", "info", "Least severe level logged: debug, info, warn or error")
+	fs.StringVar(&c.LogFormat, "log-format", LogText, "Format of log lines: text or json")
+	fs.BoolVar(&c.Quiet, "quiet", false, "Only log matched txs, balance changes and errors")
+	fs.StringVar(&c.Output, "output", OutputText, "Format of matched txs: text, json or csv")
+	fs.IntVar(&c.DataLimit, "data-limit", monitor.DefaultDataLimit, "Bytes of tx input shown in the log and json output, 0 omits it, negative shows all")
+	fs.StringVar(&c.Labels, "labels", "", "JSON file mapping addresses to names shown in the log and json output")
+	fs.StringVar(&c.PriceSource, "price-source", "", "Add the USD value of matched txs at the ETH price of this source: coingecko, an http(s) url answering JSON with a usd price, or chainlink[:feed] read through the endpoint")
+	fs.DurationVar(&c.PriceInterval, "price-interval", monitor.DefaultPriceInterval, "Refresh the -price-source price this often")
+	fs.BoolVar(&c.Raw, "raw", false, "Add the JSON of the tx as returned by the node to the json output, its fields depend on the provider")
+	fs.StringVar(&c.OutputFile, "output-file", "", "Write the json or csv output to this file instead of stdout")
+	fs.IntVar(&c.RotateSize, "rotate-size", 0, "Rotate -output-file once it reaches this many MB, 0 lets it grow")
+	fs.IntVar(&c.RotateCount, "rotate-count", 5, "Number of rotated -output-file files kept")
+	fs.StringVar(&c.MetricsAddr, "metrics-addr", "", "Serve prometheus metrics on this address, e.g. :9090")
+
+	fs.DurationVar(&c.Duration, "duration", 0, "Shut down after running this long, 0 runs until interrupted")
+	fs.DurationVar(&c.DrainTimeout, "drain-timeout", monitor.DefaultDrainTimeout, "How long to wait for in-flight work on shutdown, 0 waits forever")
+	fs.IntVar(&c.Concurrency, "concurrency", monitor.DefaultConcurrency, "Max parallel tx fetches, 0 means unbounded")
+	fs.DurationVar(&c.FetchTimeout, "fetch-timeout", monitor.DefaultFetchTimeout, "Timeout of a single tx fetch, 0 waits forever")
+	fs.DurationVar(&c.PollInterval, "poll-interval", monitor.DefaultPollInterval, "Poll interval when -endpoint is an http(s) url without subscriptions")
+	fs.DurationVar(&c.Heartbeat, "heartbeat", monitor.DefaultHeartbeat, "Log a heartbeat when no pending tx arrived for this long, 0 disables")
+	fs.DurationVar(&c.ResubInterval, "resub-interval", 0, "Renew the subscriptions this often, for providers that go silent without failing them, 0 disables")
+	fs.DurationVar(&c.ResubIdle, "resub-idle", 0, "Reconnect when no pending tx arrived for this long, 0 disables")
+	fs.DurationVar(&c.LatencyInterval, "latency-interval", monitor.DefaultLatencyInterval, "Log the average time from a pending hash to its fetched tx this often, 0 disables")
+	fs.DurationVar(&c.ErrorInterval, "error-interval", 0, "Log the RPC errors so far by type this often, they are always logged on exit")
+	fs.DurationVar(&c.MaxBackoff, "max-backoff", monitor.DefaultMaxBackoff, "Max delay between reconnect attempts, each delay is randomly jittered")
+	fs.IntVar(&c.StartAttempts, "start-attempts", monitor.DefaultStartAttempts, "Attempts at the first connection and subscription before exiting, 0 retries forever")
+	fs.IntVar(&c.DedupSize, "dedup-size", monitor.DefaultDedupSize, "Number of recent pending hashes remembered to skip re-announcements, 0 disables")
+	fs.IntVar(&c.SubBuffer, "subch-buffer", monitor.DefaultSubBuffer, "Number of pending hashes queued for fetching, more holds a busier node's bursts at the cost of memory")
+	fs.IntVar(&c.TxBuffer, "tx-buffer", monitor.DefaultTxBuffer, "Number of fetched txs queued for matching")
+	fs.Float64Var(&c.RPS, "rps", 0, "Max tx fetches per second, 0 means unlimited")
+	fs.BoolVar(&c.Check, "check", false, "Check that the endpoints can be watched, print the results and exit")
+	fs.BoolVar(&c.Once, "once", false, "Exit after the first matched tx is handled successfully, with status 1 if none was")
+	fs.DurationVar(&c.BatchWindow, "batch-window", 0, "Fetch the pending txs announced within this window, e.g. 50ms, in one batch call, 0 fetches each on its own")
+	fs.BoolVar(&c.WatchHeads, "watch-heads", false, "Also subscribe to new blocks for the current base fee and block number, over ws or IPC")
+	fs.BoolVar(&c.NonceGaps, "nonce-gaps", false, "Warn when a tx of a watched sender skips past its pending nonce, an earlier tx being stuck")
+	fs.BoolVar(&c.WatchBalance, "watch-balance", false, "Also check the balances of the watched addresses at every new block and log their changes, over ws or IPC")
+	fs.StringVar(&c.BalanceThreshold, "balance-threshold", "", "Only log balance changes larger than this many ETH with -watch-balance")
+	fs.BoolVar(&c.NoFetch, "no-fetch", false, "Hand every pending hash to the handler without fetching the tx, address and tx filters cannot apply")
+
+	fs.Var(&c.Actions, "action", "Actions on a matched tx, comma-separated or repeated: log, webhook, telegram, kafka, relay or send (default log)")
+	fs.DurationVar(&c.Coalesce, "coalesce", 0, "Collect the matches of an address for this long after the first and run the actions once on all, as one alert where they support it")
+	fs.Uint64Var(&c.Confirmations, "confirmations", 0, "Run the actions only once a matched tx is mined this many blocks deep, 0 runs them on the pending tx")
+	fs.DurationVar(&c.ConfirmTimeout, "confirm-timeout", monitor.DefaultConfirmTimeout, "Give up on a matched tx not mined within this long with -confirmations, 0 waits forever")
+	fs.StringVar(&c.SeenDB, "seen-db", "", "File recording processed tx hashes so they are skipped after a restart")
+	fs.IntVar(&c.SeenLimit, "seen-limit", monitor.DefaultSeenLimit, "Number of hashes kept in -seen-db")
+	fs.StringVar(&c.SQLite, "sqlite", "", "Record every matched tx in this SQLite database")
+	fs.StringVar(&c.Socket, "socket", "", "Stream every matched tx as a JSON line to the readers of this Unix socket")
+	fs.StringVar(&c.Webhook, "webhook", "", "POST every matched tx as JSON to this url")
+	fs.DurationVar(&c.WebhookTimeout, "webhook-timeout", monitor.DefaultWebhookTimeout, "Timeout of a webhook request")
+	fs.IntVar(&c.WebhookRetries, "webhook-retries", monitor.DefaultWebhookRetries, "Retries of a failed webhook request")
+	fs.Var(&c.WebhookHeaders, "webhook-header", `Header sent with every webhook request, such as "X-Api-Key: K", repeated for several`)
+	fs.StringVar(&c.WebhookTemplate, "webhook-template", "", `JSON envelope posted instead of the bare record, {{.Record}} standing for the record and {{.Hash}} for the tx hash, e.g. '{"method": "notify", "params": [{{.Record}}]}'`)
+	fs.StringVar(&c.WebhookSecret, "webhook-secret", "", "Sign every webhook body with HMAC-SHA256 under this secret, sent as X-Signature: sha256=<hex>")
+	fs.StringVar(&c.RelayEndpoint, "relay-endpoint", "", "Node the relay action rebroadcasts every matched tx to with eth_sendRawTransaction, such as a private mempool")
+	fs.Var(&c.RelayHeaders, "relay-header", `Header sent with every relay request, such as "Authorization: Bearer X", repeated for several`)
+	fs.Var(&c.KafkaBrokers, "kafka-brokers", "Kafka brokers, host:port, the kafka action produces to, comma-separated or repeated")
+	fs.StringVar(&c.KafkaTopic, "kafka-topic", "", "Kafka topic every matched tx is published to as JSON, keyed by its hash")
+	fs.StringVar(&c.TelegramToken, "telegram-token", "", "Telegram bot token to alert on every matched tx")
+	fs.StringVar(&c.TelegramChatID, "telegram-chat-id", "", "Telegram chat receiving the alerts")
+	fs.DurationVar(&c.TelegramTimeout, "telegram-timeout", monitor.DefaultTelegramTimeout, "Timeout of a Telegram request")
+
+	fs.StringVar(&c.KeyFile, "keyfile", "", "File holding the hex private key used by Process, defaults to $"+monitor.KeyEnv)
+	fs.StringVar(&c.Keystore, "keystore", "", "Geth keystore file holding the key used by Process, instead of -keyfile")
+	fs.StringVar(&c.PasswordFile, "keystore-password-file", "", "File holding the password of -keystore")
+	fs.StringVar(&c.TxType, "tx-type", monitor.TxLegacy, "Type of the tx sent by Process: legacy or dynamic")
+	fs.StringVar(&c.ActionTo, "action-to", "", "Recipient of the tx sent by Process, needed by action send")
+	fs.StringVar(&c.ActionValue, "action-value", "0", "Value in ETH of the tx sent by Process")
+	fs.StringVar(&c.ActionData, "action-data", "", "Hex input of the tx sent by Process, for a contract call")
+	fs.BoolVar(&c.DryRun, "dry-run", true, "Sign but never broadcast the tx of Process, set -dry-run=false to send")
+	fs.BoolVar(&c.PrintRaw, "print-raw", false, "Log the raw signed tx of Process even when it is broadcast")
+	fs.BoolVar(&c.Simulate, "simulate", false, "Run the tx of Process as an eth_call first and only send it if the call succeeds")
+	fs.StringVar(&c.MaxGasPrice, "max-gas-price", "", "Max gas price in gwei paid by Process, it skips sending above it")
+	fs.IntVar(&c.GasPadding, "gas-padding", 0, "Percentage added to the gas estimated for the tx of Process")
+	fs.DurationVar(&c.ReplaceAfter, "replace-after", 0, "Resend the tx of Process at a higher gas price if not mined after this long, 0 disables")
+	fs.IntVar(&c.MaxBumps, "max-bumps", monitor.DefaultMaxBumps, "Max gas price bumps of an unmined tx of Process")
+	fs.IntVar(&c.SendRetries, "send-retries", monitor.DefaultSendRetries, "Retries of a tx of Process failing to send on a timeout, rate limit or connection error")
+	fs.DurationVar(&c.SendBackoff, "send-backoff", monitor.DefaultSendBackoff, "Delay before the first send retry, doubled for every next one")
+}
+
+// ReadAddresses adds the addresses read from r, one per line, to the
+// designated ones. Blank lines and lines starting with # are skipped.
+func (c *Config) ReadAddresses(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		c.Addresses = append(c.Addresses, text)
+	}
+	return scanner.Err()
+}
+
+// LoadFile reads the YAML file at path over c, except for the settings
+// whose flag was given on the command line of fs.
+func (c *Config) LoadFile(path string, fs *flag.FlagSet) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	// unset keys keep the flag defaults
+	file := *c
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil {
+		return fmt.Errorf("config %s: %v", path, err)
+	}
+
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		if name, ok := flagAliases[f.Name]; ok {
+			given[name] = true
+		}
+		given[f.Name] = true
+	})
+
+	cv, fv := reflect.ValueOf(c).Elem(), reflect.ValueOf(&file).Elem()
+	for i := 0; i < cv.NumField(); i++ {
+		name := cv.Type().Field(i).Tag.Get("yaml")
+		if name != "" && !given[name] {
+			cv.Field(i).Set(fv.Field(i))
+		}
+	}
+	return nil
+}
+
+// Validate checks every setting, reporting all problems at once, and keeps
+// the parsed values for use by main.
+func (c *Config) Validate() error {
+	var errs []error
+
+	// logging comes first so the warnings below use it
+	if err := c.setupLogging(); err != nil {
+		errs = append(errs, err)
+	}
+
+	var err error
+	if c.Provider, err = monitor.ParseProvider(c.Provider); err != nil {
+		errs = append(errs, err)
+	}
+
+	switch c.Match {
+	case monitor.MatchFrom, monitor.MatchTo, monitor.MatchEither:
+	default:
+		errs = append(errs, fmt.Errorf("unknown match mode %q", c.Match))
+	}
+
+	switch c.Output {
+	case OutputText, OutputJSON, OutputCSV:
+	default:
+		errs = append(errs, fmt.Errorf("unknown output format %q", c.Output))
+	}
+	if c.Raw && c.Output != OutputJSON {
+		errs = append(errs, errors.New("-raw needs -output json"))
+	}
+	if c.RotateSize < 0 || c.RotateCount < 0 {
+		errs = append(errs, errors.New("-rotate-size and -rotate-count cannot be negative"))
+	}
+	if c.RotateSize > 0 && c.OutputFile == "" {
+		errs = append(errs, errors.New("-rotate-size needs -output-file"))
+	}
+
+	if c.TxType != monitor.TxLegacy && c.TxType != monitor.TxDynamic {
+		errs = append(errs, fmt.Errorf("unknown tx type %q", c.TxType))
+	}
+
+	errs = append(errs, checkChecksums(c.Addresses, c.StrictChecksum)...)
+	errs = append(errs, checkChecksums(c.To, c.StrictChecksum)...)
+
+	to := c.To
+	if len(to) == 0 {
+		to = c.Addresses
+	}
+	if c.NoFetch {
+		errs = append(errs, c.checkNoFetch()...)
+	} else if !c.Check && len(c.AddressPrefixes) == 0 && (len(to) == 0 || (c.Match == monitor.MatchFrom && len(c.Addresses) == 0)) {
+		errs = append(errs, errors.New("please designate a address YOU want to monitor"))
+	}
+
+	if c.senders, err = monitor.ParseAddresses(c.Addresses); err != nil {
+		errs = append(errs, err)
+	}
+	if c.recipients, err = monitor.ParseAddresses(to); err != nil && len(c.To) > 0 {
+		errs = append(errs, err)
+	}
+	if c.prefixes, err = monitor.ParseAddressPrefixes(c.AddressPrefixes); err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.toAllow, err = monitor.ParseAddresses(c.ToAllow); err != nil {
+		errs = append(errs, err)
+	}
+	if c.toDeny, err = monitor.ParseAddresses(c.ToDeny); err != nil {
+		errs = append(errs, err)
+	}
+	if c.accessList, err = monitor.ParseAddresses(c.AccessList); err != nil {
+		errs = append(errs, err)
+	}
+	if len(c.MatchTxTypes) > 0 {
+		if c.txTypes, err = monitor.ParseTxTypes(c.MatchTxTypes); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(c.Methods) > 0 {
+		if c.selectors, err = monitor.ParseSelectors(c.Methods); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if c.Contract != "" {
+		if c.ABI == "" {
+			errs = append(errs, errors.New("abi-contract needs abi"))
+		}
+		if addrs, err := monitor.ParseAddresses([]string{c.Contract}); err != nil {
+			errs = append(errs, err)
+		} else {
+			c.contract = &addrs[0]
+		}
+	}
+
+	if c.Nonce != "" {
+		if c.NonceMin != "" || c.NonceMax != "" {
+			errs = append(errs, errors.New("nonce excludes nonce-min and nonce-max"))
+		}
+		if c.nonceMin, err = parseNonce("nonce", c.Nonce); err != nil {
+			errs = append(errs, err)
+		}
+		c.nonceMax = c.nonceMin
+	} else {
+		if c.nonceMin, err = parseNonce("nonce-min", c.NonceMin); err != nil {
+			errs = append(errs, err)
+		}
+		if c.nonceMax, err = parseNonce("nonce-max", c.NonceMax); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.nonceMin != nil && c.nonceMax != nil && *c.nonceMin > *c.nonceMax {
+		errs = append(errs, fmt.Errorf("nonce-min %d is above nonce-max %d", *c.nonceMin, *c.nonceMax))
+	}
+
+	if c.MinValue != "" {
+		if c.minWei, err = monitor.ParseEther(c.MinValue); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if c.MaxValue != "" {
+		if c.maxWei, err = monitor.ParseEther(c.MaxValue); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if c.BalanceThreshold != "" {
+		if !c.WatchBalance {
+			errs = append(errs, errors.New("-balance-threshold needs -watch-balance"))
+		}
+		if c.balanceThreshold, err = monitor.ParseEther(c.BalanceThreshold); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if c.minWei != nil && c.maxWei != nil && c.minWei.Cmp(c.maxWei) > 0 {
+		errs = append(errs, fmt.Errorf("min-value %s is above max-value %s", c.MinValue, c.MaxValue))
+	}
+
+	if c.Filter != "" {
+		if c.filter, err = monitor.ParseFilterExpr(c.Filter); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if c.PriceSource != "" {
+		if _, err := monitor.ParsePriceSource(c.PriceSource, nil); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.PriceInterval <= 0 {
+		errs = append(errs, errors.New("-price-interval must be positive"))
+	}
+	if c.ResubInterval < 0 || c.ResubIdle < 0 {
+		errs = append(errs, errors.New("-resub-interval and -resub-idle must not be negative"))
+	}
+	if c.StartAttempts < 0 {
+		errs = append(errs, errors.New("-start-attempts must not be negative"))
+	}
+	if c.SubBuffer <= 0 || c.TxBuffer <= 0 {
+		errs = append(errs, errors.New("-subch-buffer and -tx-buffer must be positive"))
+	}
+
+	if c.Duration < 0 {
+		errs = append(errs, fmt.Errorf("negative duration %v", c.Duration))
+	}
+
+	if c.CreationOnly && !c.Creations {
+		errs = append(errs, errors.New("-creation-only and -creations=false exclude every tx"))
+	}
+	if c.NonZeroData && c.ZeroDataOnly {
+		errs = append(errs, errors.New("-non-zero-data and -zero-data-only exclude every tx"))
+	}
+
+	if c.MinGasPrice != "" {
+		if c.minGasPrice, err = monitor.ParseGwei(c.MinGasPrice); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if c.MaxGasPrice != "" {
+		if c.maxGasPrice, err = monitor.ParseGwei(c.MaxGasPrice); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if (c.TelegramToken == "") != (c.TelegramChatID == "") {
+		errs = append(errs, errors.New("telegram-token and telegram-chat-id must be set together"))
+	}
+
+	var keyErr error
+	if c.Keystore != "" {
+		c.key, keyErr = monitor.LoadKeystore(c.Keystore, c.PasswordFile)
+	} else {
+		c.key, keyErr = monitor.LoadKey(c.KeyFile)
+	}
+	if keyErr != nil {
+		errs = append(errs, fmt.Errorf("load private key: %v", keyErr))
+	}
+
+	if len(c.Actions) == 0 {
+		c.Actions = stringList{ActionLog}
+	}
+	for _, a := range c.Actions {
+		switch a {
+		case ActionLog:
+		case ActionWebhook:
+			if c.Webhook == "" {
+				errs = append(errs, errors.New("action webhook needs -webhook"))
+			}
+			if c.webhookHeader, err = monitor.ParseHeaders(c.WebhookHeaders); err != nil {
+				errs = append(errs, err)
+			}
+			if c.WebhookTemplate != "" {
+				if c.webhookTemplate, err = monitor.ParseWebhookTemplate(c.WebhookTemplate); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		case ActionKafka:
+			if len(c.KafkaBrokers) == 0 || c.KafkaTopic == "" {
+				errs = append(errs, errors.New("action kafka needs -kafka-brokers and -kafka-topic"))
+			}
+		case ActionRelay:
+			if c.RelayEndpoint == "" {
+				errs = append(errs, errors.New("action relay needs -relay-endpoint"))
+			}
+			if c.relayHeader, err = monitor.ParseHeaders(c.RelayHeaders); err != nil {
+				errs = append(errs, err)
+			}
+		case ActionTelegram:
+			if c.TelegramToken == "" {
+				errs = append(errs, errors.New("action telegram needs -telegram-token and -telegram-chat-id"))
+			}
+		case ActionSend:
+			if c.key == nil && keyErr == nil {
+				errs = append(errs, errors.New("action send needs -keyfile, -keystore or $"+monitor.KeyEnv))
+			}
+			errs = append(errs, c.checkResponse()...)
+		default:
+			if _, ok := monitor.LookupHandler(a); !ok {
+				errs = append(errs, fmt.Errorf("unknown action %q", a))
+			}
+		}
+	}
+	if c.Coalesce < 0 {
+		errs = append(errs, errors.New("-coalesce must not be negative"))
+	}
+	if c.ConfirmTimeout < 0 {
+		errs = append(errs, errors.New("-confirm-timeout must not be negative"))
+	}
+	if c.Webhook != "" && !c.hasAction(ActionWebhook) {
+		slog.Warn("-webhook is set but -action has no webhook")
+	}
+	if c.RelayEndpoint != "" && !c.hasAction(ActionRelay) {
+		slog.Warn("-relay-endpoint is set but -action has no relay")
+	}
+	if c.KafkaTopic != "" && !c.hasAction(ActionKafka) {
+		slog.Warn("-kafka-topic is set but -action has no kafka")
+	}
+	if c.TelegramToken != "" && !c.hasAction(ActionTelegram) {
+		slog.Warn("-telegram-token is set but -action has no telegram")
+	}
+
+	if len(c.Endpoints) == 0 {
+		c.Endpoints = stringList{defaultEndpoint}
+	}
+	if c.pool, err = monitor.NewEndpointPool(c.Endpoints, c.Policy); err != nil {
+		errs = append(errs, err)
+	} else if c.pool.Header, err = monitor.ParseHeaders(c.Headers); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkResponse parses the recipient, value and input of the tx of the send
+// action.
+func (c *Config) checkResponse() []error {
+	var errs []error
+	if !common.IsHexAddress(c.ActionTo) {
+		errs = append(errs, errors.New("action send needs -action-to, the recipient of its tx"))
+	} else {
+		c.actionTo = common.HexToAddress(c.ActionTo)
+	}
+
+	var err error
+	if c.actionValue, err = monitor.ParseEther(c.ActionValue); err != nil {
+		errs = append(errs, fmt.Errorf("-action-value: %w", err))
+	}
+	if c.ActionData != "" {
+		if c.actionData, err = hexutil.Decode(c.ActionData); err != nil {
+			errs = append(errs, fmt.Errorf("-action-data: %w", err))
+		}
+	}
+	return errs
+}
+
+// checkNoFetch reports the settings that need the tx body, which -no-fetch
+// never fetches.
+func (c *Config) checkNoFetch() []error {
+	var unusable []string
+	for name, set := range map[string]bool{
+		"-address":             len(c.Addresses) > 0 || len(c.To) > 0,
+		"-address-prefix":      len(c.AddressPrefixes) > 0,
+		"-min-value":           c.MinValue != "",
+		"-max-value":           c.MaxValue != "",
+		"-min-gas-price":       c.MinGasPrice != "",
+		"-method":              len(c.Methods) > 0,
+		"-nonce":               c.Nonce != "" || c.NonceMin != "" || c.NonceMax != "",
+		"-to-allow":            len(c.ToAllow) > 0 || len(c.ToDeny) > 0 || !c.Creations,
+		"-accesslist-contains": len(c.AccessList) > 0,
+		"-match-tx-type":       len(c.MatchTxTypes) > 0,
+		"-creation-only":       c.CreationOnly,
+		"-non-zero-data":       c.NonZeroData,
+		"-price-source":        c.PriceSource != "",
+		"-zero-data-only":      c.ZeroDataOnly,
+		"-filter":              c.Filter != "",
+		"-abi":                 c.ABI != "",
+		"-from-block":          c.FromBlock != "",
+		"-replay":              c.Replay != "",
+		"-raw":                 c.Raw,
+		"-nonce-gaps":          c.NonceGaps,
+		"-confirmations":       c.Confirmations > 0,
+		"-coalesce":            c.Coalesce > 0,
+		"-output csv":          c.Output == OutputCSV,
+		"-sqlite":              c.SQLite != "",
+		"-socket":              c.Socket != "",
+	} {
+		if set {
+			unusable = append(unusable, name)
+		}
+	}
+	for _, a := range c.Actions {
+		if a != ActionLog {
+			unusable = append(unusable, "-action "+a)
+		}
+	}
+	sort.Strings(unusable)
+
+	var errs []error
+	for _, name := range unusable {
+		errs = append(errs, fmt.Errorf("%s needs the tx, which -no-fetch does not fetch", name))
+	}
+	return errs
+}
+
+// setupLogging installs the default slog logger on stderr as configured.
+func (c *Config) setupLogging() error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		return fmt.Errorf("unknown log level %q", c.LogLevel)
+	}
+	if c.Debug {
+		level = slog.LevelDebug
+	}
+	if c.Quiet && c.Debug {
+		return errors.New("-quiet and -debug exclude each other")
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var h slog.Handler
+	switch c.LogFormat {
+	case LogText:
+		h = slog.NewTextHandler(os.Stderr, opts)
+	case LogJSON:
+		h = slog.NewJSONHandler(os.Stderr, opts)
+	default:
+		return fmt.Errorf("unknown log format %q", c.LogFormat)
+	}
+	if c.Quiet {
+		h = quietHandler{h}
+	}
+	slog.SetDefault(slog.New(h))
+	return nil
+}
+
+// quietHandler passes on errors, matched txs and balance changes only.
+type quietHandler struct {
+	slog.Handler
+}
+
+func (h quietHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelError && r.Message != monitor.MatchedMsg && r.Message != monitor.BalanceChangedMsg {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h quietHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return quietHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h quietHandler) WithGroup(name string) slog.Handler {
+	return quietHandler{h.Handler.WithGroup(name)}
+}
+
+// hasAction reports whether action is taken on matched txs.
+func (c *Config) hasAction(action string) bool {
+	for _, a := range c.Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNonce parses the nonce setting name, nil if unset.
+func parseNonce(name, s string) (*uint64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q", name, s)
+	}
+	return &n, nil
+}
+
+// checkChecksums warns about entries failing their EIP-55 checksum, or
+// returns them as errors if strict.
+func checkChecksums(list []string, strict bool) []error {
+	var errs []error
+	for _, s := range list {
+		if err := monitor.VerifyChecksum(s); err != nil {
+			if strict {
+				errs = append(errs, err)
+			} else {
+				slog.Warn(err.Error())
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,588 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/dzshubin/HackInEthereum/monitorTx/monitor"
+)
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: monitor  [-version] [-config file.yaml] [-address add[,add...]] [-to add[,add...]] [-match from|to|either] [-action log|webhook|telegram|kafka|relay|send[,...]] [-keyfile file | -keystore file] [-action-to add [-action-value eth] [-action-data hex]] [-tx-type legacy|dynamic] [-dry-run=false] [-output text|json|csv] [-output-file file] [-min-value eth] [-max-value eth] [-method 0x12345678[,...]] [-abi file [-abi-contract add]] [-endpoint ws-url|http-url|ipc-path[,...]] [-endpoint-policy failover|round-robin]
+Options:
+`)
+	flag.PrintDefaults()
+}
+
+// firstMatch serializes the handlers of -once until one of them succeeds,
+// which cancels the run.
+type firstMatch struct {
+	mu      sync.Mutex
+	matched bool
+	cancel  func()
+}
+
+// handle runs f, which reports its success, unless a match already
+// succeeded.
+func (o *firstMatch) handle(f func() bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.matched {
+		return
+	}
+	if f() {
+		o.matched = true
+		o.cancel()
+	}
+}
+
+// done reports whether a match succeeded.
+func (o *firstMatch) done() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.matched
+}
+
+// check prints the results of monitor.CheckEndpoint for every endpoint and
+// returns the exit code, 1 if any of them failed.
+func check(endpoints []string, header http.Header) int {
+	code := 0
+	for _, url := range endpoints {
+		fmt.Println(url)
+		steps := monitor.CheckEndpoint(context.Background(), url, header, monitor.DefaultCheckTimeout)
+		for _, s := range steps {
+			if s.Err != nil {
+				fmt.Printf("  %-13s FAIL %v\n", s.Name+":", s.Err)
+			} else {
+				fmt.Printf("  %-13s %s\n", s.Name+":", s.Result)
+			}
+		}
+		if !monitor.CheckPassed(steps) {
+			code = 1
+		}
+	}
+	return code
+}
+
+func main() {
+
+	var cfg Config
+	cfg.RegisterFlags(flag.CommandLine)
+	configFile := flag.String("config", "", "YAML file of settings keyed by flag name, flags given on the command line override it")
+	showVersion := flag.Bool("version", false, "Print the version and build details and exit")
+
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(versionString())
+		return
+	}
+
+	if *configFile != "" {
+		if err := cfg.LoadFile(*configFile, flag.CommandLine); err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	if cfg.AddressStdin {
+		// a terminal would wait for input nobody means to type
+		if fi, err := os.Stdin.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+			log.Fatalln("-address-stdin needs the addresses piped in")
+		}
+		if err := cfg.ReadAddresses(os.Stdin); err != nil {
+			log.Fatalln("read addresses from stdin:", err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		printUsage()
+		os.Exit(2)
+	}
+
+	v, c, d := buildInfo()
+	slog.Info("Starting monitor", "version", v, "commit", c, "built", d)
+
+	if cfg.Check {
+		os.Exit(check(cfg.Endpoints, cfg.pool.Header))
+	}
+
+	// exit only once the deferred closes below have flushed everything
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
+
+	m, err := monitor.ConnectMonitorPool(context.Background(), cfg.pool, cfg.senders, cfg.StartAttempts)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer m.Close()
+
+	if cfg.ChainID != 0 {
+		chainID := new(big.Int).SetUint64(cfg.ChainID)
+		if chainID.Cmp(m.ChainID) != 0 {
+			slog.Warn("-chain-id differs from the node's chain ID", "chainID", chainID, "node", m.ChainID)
+		}
+		m.ChainID = chainID
+	}
+
+	m.Recipients = monitor.AddressSet(cfg.recipients)
+	m.Prefixes = cfg.prefixes
+	m.Match = cfg.Match
+	m.Provider = cfg.Provider
+	m.DrainTimeout = cfg.DrainTimeout
+	m.Concurrency = cfg.Concurrency
+	m.FetchTimeout = cfg.FetchTimeout
+	m.PollInterval = cfg.PollInterval
+	m.Heartbeat = cfg.Heartbeat
+	m.LatencyInterval = cfg.LatencyInterval
+	m.ErrorInterval = cfg.ErrorInterval
+	m.ResubInterval = cfg.ResubInterval
+	m.ResubIdle = cfg.ResubIdle
+	m.MaxBackoff = cfg.MaxBackoff
+	m.StartAttempts = cfg.StartAttempts
+	m.DedupSize = cfg.DedupSize
+	m.SubBuffer = cfg.SubBuffer
+	m.TxBuffer = cfg.TxBuffer
+	m.RPS = cfg.RPS
+	m.WatchHeads = cfg.WatchHeads
+	m.NonceGaps = cfg.NonceGaps
+	m.BatchWindow = cfg.BatchWindow
+	m.DataLimit = cfg.DataLimit
+
+	if cfg.txTypes != nil {
+		m.Filters = append(m.Filters, monitor.TypeFilter(cfg.txTypes))
+		_, m.ExpectBlobs = cfg.txTypes[types.BlobTxType]
+	}
+
+	if len(cfg.accessList) > 0 {
+		m.Filters = append(m.Filters, monitor.AccessListFilter(monitor.AddressSet(cfg.accessList)))
+	}
+
+	if len(cfg.toAllow) > 0 || len(cfg.toDeny) > 0 || !cfg.Creations {
+		m.Filters = append(m.Filters, monitor.ToFilter(monitor.AddressSet(cfg.toAllow), monitor.AddressSet(cfg.toDeny), cfg.Creations))
+	}
+
+	if cfg.CreationOnly {
+		m.Filters = append(m.Filters, monitor.CreationFilter())
+	}
+
+	if cfg.NonZeroData || cfg.ZeroDataOnly {
+		m.Filters = append(m.Filters, monitor.DataFilter(cfg.NonZeroData))
+	}
+
+	if cfg.filter != nil {
+		m.Filters = append(m.Filters, cfg.filter.Filter(m.ChainID))
+	}
+
+	if cfg.nonceMin != nil || cfg.nonceMax != nil {
+		m.Filters = append(m.Filters, monitor.NonceFilter(cfg.nonceMin, cfg.nonceMax))
+	}
+
+	if cfg.selectors != nil {
+		m.Filters = append(m.Filters, monitor.SelectorFilter(cfg.selectors))
+	}
+
+	if cfg.minGasPrice != nil {
+		m.Filters = append(m.Filters, monitor.GasPriceFilter(cfg.minGasPrice))
+	}
+
+	if cfg.ABI != "" {
+		if m.ABI, err = monitor.LoadABI(cfg.ABI, cfg.contract); err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	if cfg.Labels != "" {
+		if m.Labels, err = monitor.LoadLabels(cfg.Labels); err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	if cfg.PriceSource != "" {
+		src, err := monitor.ParsePriceSource(cfg.PriceSource, m.Client)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		m.Price = monitor.NewPriceFeed(src, cfg.PriceInterval)
+	}
+
+	if cfg.minWei != nil || cfg.maxWei != nil {
+		m.Filters = append(m.Filters, monitor.ValueRangeFilter(cfg.minWei, cfg.maxWei))
+	}
+
+	if cfg.MetricsAddr != "" {
+		http.Handle("/metrics", promhttp.Handler())
+		go func() {
+			log.Fatalln(http.ListenAndServe(cfg.MetricsAddr, nil))
+		}()
+	}
+
+	responder := &monitor.Responder{
+		Key:         cfg.key,
+		TxType:      cfg.TxType,
+		To:          &cfg.actionTo,
+		Value:       cfg.actionValue,
+		Data:        cfg.actionData,
+		ChainID:     m.ChainID,
+		DryRun:      cfg.DryRun,
+		PrintRaw:    cfg.PrintRaw,
+		Simulate:    cfg.Simulate,
+		MaxGasPrice: cfg.maxGasPrice,
+		GasPadding:  cfg.GasPadding,
+
+		ReplaceAfter: cfg.ReplaceAfter,
+		MaxBumps:     cfg.MaxBumps,
+		SendRetries:  cfg.SendRetries,
+		SendBackoff:  cfg.SendBackoff,
+	}
+
+	// ctx is the run, cancelled on interrupt or once it is over
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// the built-in actions join the handler registry once configured
+	monitor.RegisterHandler(ActionSend, responder)
+	if cfg.hasAction(ActionWebhook) {
+		webhook := monitor.NewWebhook(cfg.Webhook, cfg.WebhookTimeout, cfg.WebhookRetries)
+		webhook.Header = cfg.webhookHeader
+		webhook.Template = cfg.webhookTemplate
+		if cfg.WebhookSecret != "" {
+			webhook.Secret = []byte(cfg.WebhookSecret)
+		}
+		monitor.RegisterHandler(ActionWebhook, webhook)
+	}
+	if cfg.hasAction(ActionRelay) {
+		relay, err := monitor.DialRelay(ctx, cfg.RelayEndpoint, cfg.relayHeader)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		defer relay.Close()
+		monitor.RegisterHandler(ActionRelay, relay)
+	}
+	if cfg.hasAction(ActionKafka) {
+		k := monitor.NewKafka(cfg.KafkaBrokers, cfg.KafkaTopic, monitor.DefaultKafkaBuffer)
+		defer k.Close()
+		monitor.RegisterHandler(ActionKafka, k)
+	}
+	if cfg.hasAction(ActionTelegram) {
+		monitor.RegisterHandler(ActionTelegram, monitor.NewTelegram(cfg.TelegramToken, cfg.TelegramChatID, cfg.TelegramTimeout))
+	}
+	handlers, err := monitor.ResolveHandlers(cfg.Actions)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	// act runs every action on a match, with -coalesce on the matches of an
+	// address at once and with -confirmations once they are mined
+	var act monitor.Handler = monitor.BatchHandlerFunc(func(ctx context.Context, batch []monitor.Match) error {
+		var failed error
+		for i, h := range handlers {
+			if err := monitor.HandleMatches(ctx, h, batch); err != nil {
+				slog.Error("Action failed", "action", cfg.Actions[i], "hash", batch[0].Tx.Hash(), "txs", len(batch), "err", err)
+				failed = err
+			}
+		}
+		return failed
+	})
+	if cfg.Coalesce > 0 {
+		act = &monitor.Coalescer{Window: cfg.Coalesce, Next: act, Key: func(match monitor.Match) common.Address {
+			// batch by the watched side of the tx
+			if _, ok := m.Senders[match.Record.From]; ok || match.Record.To == nil {
+				return match.Record.From
+			}
+			return *match.Record.To
+		}}
+	}
+	if cfg.Confirmations > 0 {
+		act = &monitor.Confirmed{Confirmations: cfg.Confirmations, Timeout: cfg.ConfirmTimeout, Next: act}
+	}
+
+	var out io.Writer = os.Stdout
+	var csvOut *monitor.CSVWriter
+	if cfg.RotateSize > 0 {
+		// the rotating file writes the csv header to every new file
+		var header []byte
+		if cfg.Output == OutputCSV {
+			header = monitor.CSVHeader()
+		}
+		f, err := monitor.OpenRotatingFile(cfg.OutputFile, int64(cfg.RotateSize)<<20, cfg.RotateCount, header)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		defer f.Close()
+		out = f
+		if cfg.Output == OutputCSV {
+			csvOut = monitor.NewCSVWriter(out, false)
+		}
+	} else {
+		f := os.Stdout
+		if cfg.OutputFile != "" {
+			if f, err = os.OpenFile(cfg.OutputFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644); err != nil {
+				log.Fatalln(err)
+			}
+			defer f.Close()
+		}
+		out = f
+		if cfg.Output == OutputCSV {
+			// a file appended to already has its header
+			info, err := f.Stat()
+			csvOut = monitor.NewCSVWriter(out, err != nil || !info.Mode().IsRegular() || info.Size() == 0)
+		}
+	}
+
+	var db *monitor.SQLiteStore
+	if cfg.SQLite != "" {
+		if db, err = monitor.OpenSQLiteStore(cfg.SQLite); err != nil {
+			log.Fatalln(err)
+		}
+		defer db.Close()
+	}
+
+	var sock *monitor.Socket
+	if cfg.Socket != "" {
+		if sock, err = monitor.ListenSocket(cfg.Socket, monitor.DefaultSocketBuffer); err != nil {
+			log.Fatalln(err)
+		}
+		defer sock.Close()
+	}
+
+	var seen *monitor.SeenStore
+	if cfg.SeenDB != "" {
+		if seen, err = monitor.OpenSeenStore(cfg.SeenDB, cfg.SeenLimit); err != nil {
+			log.Fatalln(err)
+		}
+		defer seen.Close()
+	}
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt)
+
+	// the first interrupt shuts down gracefully, a second one while that
+	// drains exits at once
+	go func() {
+		<-sigc
+		slog.Info("Interrupted, shutting down, interrupt again to exit now")
+		cancel()
+
+		<-sigc
+		slog.Warn("Interrupted again, exiting without waiting for in-flight work")
+		os.Exit(130)
+	}()
+
+	if m.Price != nil {
+		go m.Price.Run(ctx)
+	}
+
+	if cfg.Duration > 0 {
+		timer := time.AfterFunc(cfg.Duration, func() {
+			slog.Info("Duration elapsed, shutting down", "duration", cfg.Duration)
+			cancel()
+		})
+		defer timer.Stop()
+	}
+
+	first := &firstMatch{cancel: cancel}
+
+	// handle reports whether t was handled successfully, raw is its JSON
+	// with -raw
+	handle := func(t *types.Transaction, raw json.RawMessage) bool {
+		if seen != nil && seen.Seen(t.Hash()) {
+			slog.Debug("Tx already processed, skipped", "hash", t.Hash())
+			return false
+		}
+
+		from, _ := monitor.Sender(m.ChainID, t)
+		record := monitor.NewTxRecord(t, from)
+		record.DataLimit = cfg.DataLimit
+		record.Raw = raw
+		record.Label(m.Labels)
+		if usd, ok := m.Price.ValueUSD(t.Value()); ok {
+			record.ValueUSD = &usd
+		}
+		if record.Tip != nil {
+			record.EffectiveGasPrice = monitor.EffectiveGasPrice(t, m.BaseFee())
+		}
+		if m.ABI != nil {
+			record.Call, _ = m.ABI.Decode(t)
+		}
+
+		switch cfg.Output {
+		case OutputJSON:
+			line, err := json.Marshal(record)
+			if err != nil {
+				slog.Error("Marshal tx", "hash", t.Hash(), "err", err)
+			} else {
+				fmt.Fprintln(out, string(line))
+			}
+		case OutputCSV:
+			if err := csvOut.Write(record, time.Now()); err != nil {
+				slog.Error("Write tx", "hash", t.Hash(), "err", err)
+			}
+		}
+
+		if sock != nil {
+			if err := sock.Publish(record); err != nil {
+				slog.Error("Publish tx", "hash", t.Hash(), "err", err)
+			}
+		}
+		if db != nil {
+			if err := db.Add(record, time.Now()); err != nil {
+				slog.Error("Record tx", "hash", t.Hash(), "err", err)
+			}
+		}
+
+		match := monitor.Match{Tx: t, Record: record, Client: m.Client()}
+		ok := act.Handle(ctx, match) == nil
+
+		if seen != nil && ok {
+			if err := seen.Add(t.Hash()); err != nil {
+				slog.Error("Record tx", "hash", t.Hash(), "err", err)
+			}
+		}
+		return ok
+	}
+
+	rawHandler := func(t *types.Transaction, raw json.RawMessage) {
+		if cfg.Once {
+			first.handle(func() bool { return handle(t, raw) })
+		} else {
+			handle(t, raw)
+		}
+	}
+	handler := func(t *types.Transaction) { rawHandler(t, nil) }
+
+	if cfg.NoFetch {
+		handleHash := func(h common.Hash) bool {
+			if seen != nil && seen.Seen(h) {
+				return false
+			}
+
+			slog.Info("Pending tx", "hash", h)
+			if cfg.Output == OutputJSON {
+				line, _ := json.Marshal(struct {
+					Hash common.Hash `json:"hash"`
+				}{h})
+				fmt.Fprintln(out, string(line))
+			}
+
+			if seen != nil {
+				if err := seen.Add(h); err != nil {
+					slog.Error("Record tx", "hash", h, "err", err)
+				}
+			}
+			return true
+		}
+
+		err := m.RunHashes(ctx, func(h common.Hash) {
+			if cfg.Once {
+				first.handle(func() bool { return handleHash(h) })
+			} else {
+				handleHash(h)
+			}
+		})
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if cfg.Once && !first.done() {
+			exitCode = 1
+		}
+		fmt.Printf("shutting down by outside...\n")
+		return
+	}
+
+	if cfg.Replay != "" {
+		f, err := os.Open(cfg.Replay)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		defer f.Close()
+
+		read, matched, err := m.Replay(ctx, f, handler)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		slog.Info("Replayed", "file", cfg.Replay, "hashes", read, "matched", matched)
+		if cfg.Once && !first.done() {
+			exitCode = 1
+		}
+		return
+	}
+
+	if cfg.FromBlock != "" {
+		head, err := m.Client().BlockNumber(ctx)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		start, err := monitor.ParseFromBlock(cfg.FromBlock, head)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		if err := m.Backfill(ctx, start, handler); err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	if cfg.WatchBalance {
+		watched := monitor.AddressSet(append(append([]common.Address{}, cfg.senders...), cfg.recipients...))
+		var addrs []common.Address
+		for addr := range watched {
+			addrs = append(addrs, addr)
+		}
+
+		go func() {
+			// the change is logged by WatchBalances, the actions able to
+			// tell of it are handed it too
+			err := m.WatchBalances(ctx, addrs, cfg.balanceThreshold, func(c monitor.BalanceChange) {
+				if cfg.Output == OutputJSON {
+					line, _ := json.Marshal(c)
+					fmt.Fprintln(out, string(line))
+				}
+				for i, h := range handlers {
+					if err := monitor.HandleBalance(ctx, h, c); err != nil {
+						slog.Error("Action failed", "action", cfg.Actions[i], "address", c.Address, "err", err)
+					}
+				}
+			})
+			if err != nil {
+				slog.Error("Watch balances", "err", err)
+			}
+		}()
+	}
+
+	// a match of the backfill already ended a -once run
+	if ctx.Err() == nil {
+		var err error
+		if cfg.Raw {
+			err = m.RunRaw(ctx, rawHandler)
+		} else {
+			err = m.Run(ctx, handler)
+		}
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+	if cfg.Once && !first.done() {
+		exitCode = 1
+	}
+	fmt.Printf("shutting down by outside...\n")
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Build details, set by the linker:
+//
+//	go build -ldflags "-X main.version=v1.2.0 -X main.commit=$(git rev-parse --short HEAD) -X main.date=$(date -u +%FT%TZ)" ./cmd/monitor
+var (
+	version string
+	commit  string
+	date    string
+)
+
+// buildInfo returns the version, commit and build date of the binary. The
+// ones not set by the linker come from the build info of the go command,
+// which knows the module version and, when built in a checkout, its commit.
+func buildInfo() (v, c, d string) {
+	v, c, d = version, commit, date
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if v == "" {
+			v = info.Main.Version
+		}
+		var modified bool
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if c == "" {
+					c = s.Value
+				}
+			case "vcs.time":
+				if d == "" {
+					d = s.Value
+				}
+			case "vcs.modified":
+				modified = s.Value == "true"
+			}
+		}
+		if commit == "" && c != "" {
+			c = c[:min(len(c), 12)]
+			if modified {
+				c += "-dirty"
+			}
+		}
+	}
+
+	if v == "" {
+		v = "(devel)"
+	}
+	if c == "" {
+		c = "unknown"
+	}
+	if d == "" {
+		d = "unknown"
+	}
+	return v, c, d
+}
+
+// versionString is the output of -version.
+func versionString() string {
+	v, c, d := buildInfo()
+	return fmt.Sprintf("monitor %s (commit %s, built %s, %s)", v, c, d, runtime.Version())
+}
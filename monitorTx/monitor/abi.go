@@ -0,0 +1,88 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ContractABI decodes the calls made to a contract.
+type ContractABI struct {
+	ABI abi.ABI
+
+	// Address, if set, restricts decoding to the txs sent to it.
+	Address *common.Address
+}
+
+// LoadABI reads the JSON ABI at path of the contract at address.
+func LoadABI(path string, address *common.Address) (*ContractABI, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	parsed, err := abi.JSON(f)
+	if err != nil {
+		return nil, fmt.Errorf("abi %s: %v", path, err)
+	}
+	return &ContractABI{ABI: parsed, Address: address}, nil
+}
+
+// CallArg is a named argument of a decoded call.
+type CallArg struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// Call is a contract call decoded from calldata.
+type Call struct {
+	Method string    `json:"method"`
+	Args   []CallArg `json:"args"`
+}
+
+func (c *Call) String() string {
+	args := make([]string, len(c.Args))
+	for i, a := range c.Args {
+		args[i] = fmt.Sprintf("%s=%v", a.Name, a.Value)
+	}
+	return c.Method + "(" + strings.Join(args, ", ") + ")"
+}
+
+// Decode decodes the input of tx. It returns nil without error when tx is
+// not sent to the contract or calls no method of the ABI.
+func (c *ContractABI) Decode(tx *types.Transaction) (*Call, error) {
+	if c.Address != nil && (tx.To() == nil || *tx.To() != *c.Address) {
+		return nil, nil
+	}
+
+	data := tx.Data()
+	if len(data) < SelectorLength {
+		return nil, nil
+	}
+	method, err := c.ABI.MethodById(data[:SelectorLength])
+	if err != nil {
+		return nil, nil
+	}
+
+	values, err := method.Inputs.Unpack(data[SelectorLength:])
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %v", method.Name, err)
+	}
+
+	call := &Call{Method: method.Name, Args: make([]CallArg, len(values))}
+	for i, v := range values {
+		in := method.Inputs[i]
+		name := in.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		call.Args[i] = CallArg{Name: name, Type: in.Type.String(), Value: v}
+	}
+	return call, nil
+}
@@ -0,0 +1,54 @@
+package monitor
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestContractABI(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "erc20.json")
+	if err := os.WriteFile(path, []byte(erc20JSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	token := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	c, err := LoadABI(path, &token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// transfer(0x003b..., 1000)
+	transfer := hexutil.MustDecode("0xa9059cbb" +
+		"000000000000000000000000003be5df5fef651ef0c59cd175c73ca1415f53ea" +
+		"00000000000000000000000000000000000000000000000000000000000003e8")
+	callTx := func(to common.Address, data []byte) *types.Transaction {
+		return types.NewTransaction(0, to, big.NewInt(0), 60000, big.NewInt(1), data)
+	}
+
+	call, err := c.Decode(callTx(token, transfer))
+	if err != nil || call == nil {
+		t.Fatalf("transfer: got %v, %v", call, err)
+	}
+	if got, want := call.String(), "transfer(to=0x003be5Df5FeF651EF0C59cD175c73ca1415f53eA, value=1000)"; got != want {
+		t.Errorf("transfer: got %s, want %s", got, want)
+	}
+
+	// other contracts and unknown methods are left undecoded
+	if call, err := c.Decode(callTx(common.Address{}, transfer)); call != nil || err != nil {
+		t.Errorf("other contract: got %v, %v", call, err)
+	}
+	if call, err := c.Decode(callTx(token, []byte{1, 2, 3, 4})); call != nil || err != nil {
+		t.Errorf("unknown method: got %v, %v", call, err)
+	}
+
+	// truncated arguments fail
+	if _, err := c.Decode(callTx(token, transfer[:40])); err == nil {
+		t.Error("truncated transfer decoded")
+	}
+}
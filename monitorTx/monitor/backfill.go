@@ -0,0 +1,64 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ParseFromBlock resolves a block number, or "latest-K" for the K blocks
+// before head, to the first block to scan.
+func ParseFromBlock(spec string, head uint64) (uint64, error) {
+	if rest, ok := strings.CutPrefix(spec, "latest-"); ok {
+		k, err := strconv.ParseUint(rest, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid block %q: %v", spec, err)
+		}
+		if k > head {
+			return 0, nil
+		}
+		return head - k, nil
+	}
+
+	n, err := strconv.ParseUint(spec, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid block %q: %v", spec, err)
+	}
+	if n > head {
+		return 0, fmt.Errorf("block %d is beyond head %d", n, head)
+	}
+	return n, nil
+}
+
+// Backfill runs the txs of the blocks from `from` up to the current head
+// through the same matching as Run.
+func (m *Monitor) Backfill(ctx context.Context, from uint64, handler func(*types.Transaction)) error {
+	client := m.Client()
+
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	slog.Info("Scanning blocks", "from", from, "to", head)
+	m.refreshHead(ctx)
+
+	for n := from; n <= head; n++ {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		block, err := client.BlockByNumber(ctx, new(big.Int).SetUint64(n))
+		if err != nil {
+			return fmt.Errorf("block %d: %v", n, err)
+		}
+		for _, tx := range block.Transactions() {
+			m.dispatch(ctx, tx, handler)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package monitor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestParseFromBlock(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    uint64
+		wantErr bool
+	}{
+		{"90", 90, false},
+		{"100", 100, false},
+		{"101", 0, true},
+		{"latest-10", 90, false},
+		{"latest-0", 100, false},
+		{"latest-1000", 0, false},
+		{"latest-", 0, true},
+		{"earliest", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFromBlock(tt.spec, 100)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%q: got %d, %v, want %d", tt.spec, got, err, tt.want)
+		}
+	}
+}
+
+func TestBackfill(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+
+	send := func(k *ecdsa.PrivateKey, nonce uint64, wei int64) *types.Transaction {
+		tx, err := types.SignTx(types.NewTransaction(nonce, common.Address{1}, big.NewInt(wei), 21000, big.NewInt(1), nil), signer, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+
+	large, small, foreign := send(key, 0, 100), send(key, 1, 1), send(other, 0, 100)
+	client := newFakeClient()
+	client.blocks = []*types.Block{block(0), block(1, small), block(2, large, foreign)}
+
+	m := &Monitor{
+		Senders: AddressSet([]common.Address{crypto.PubkeyToAddress(key.PublicKey)}),
+		Match:   MatchFrom,
+		Filters: []Filter{MinValueFilter(big.NewInt(50))},
+		ChainID: client.chainID,
+		client:  client,
+	}
+
+	var mu sync.Mutex
+	var got []common.Hash
+	err := m.Backfill(context.Background(), 1, func(tx *types.Transaction) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, tx.Hash())
+	})
+	m.wg.Wait()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != large.Hash() {
+		t.Errorf("handled %x, want only 0x%x", got, large.Hash())
+	}
+}
@@ -0,0 +1,39 @@
+package monitor
+
+import (
+	"log/slog"
+	"time"
+)
+
+const (
+	// backlogWarnRatio of the subscription buffer filled logs a warning.
+	backlogWarnRatio = 0.9
+
+	// backlogWarnInterval throttles the backlog warnings.
+	backlogWarnInterval = 10 * time.Second
+)
+
+// backlogWatch warns when pending hashes queue up faster than they are
+// consumed. A full buffer stalls the subscription, which then drops or
+// overflows, so every hash received at capacity is counted as a likely drop.
+type backlogWatch struct {
+	capacity int
+	lastWarn time.Time
+	drops    uint64
+}
+
+// observe records the backlog seen when a hash is received.
+func (b *backlogWatch) observe(backlog int, now time.Time) {
+	subBacklog.Set(float64(backlog))
+
+	if backlog >= b.capacity {
+		b.drops++
+		hashesDropped.Inc()
+	}
+
+	if float64(backlog) >= backlogWarnRatio*float64(b.capacity) && now.Sub(b.lastWarn) >= backlogWarnInterval {
+		slog.Warn("Pending hashes backing up, raise -concurrency or -rps, or add a node",
+			"backlog", backlog, "capacity", b.capacity, "dropped", b.drops)
+		b.lastWarn = now
+	}
+}
@@ -0,0 +1,33 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBacklogWatch(t *testing.T) {
+	b := &backlogWatch{capacity: 10}
+	now := time.Now()
+
+	b.observe(5, now)
+	if !b.lastWarn.IsZero() || b.drops != 0 {
+		t.Fatalf("warned or dropped at half capacity: %+v", b)
+	}
+
+	b.observe(9, now)
+	if !b.lastWarn.Equal(now) || b.drops != 0 {
+		t.Fatalf("no warning near capacity: %+v", b)
+	}
+
+	// full, but the warning is throttled
+	b.observe(10, now.Add(time.Second))
+	if !b.lastWarn.Equal(now) || b.drops != 1 {
+		t.Fatalf("full: %+v", b)
+	}
+
+	later := now.Add(backlogWarnInterval)
+	b.observe(10, later)
+	if !b.lastWarn.Equal(later) || b.drops != 2 {
+		t.Fatalf("after the interval: %+v", b)
+	}
+}
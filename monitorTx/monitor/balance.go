@@ -0,0 +1,146 @@
+package monitor
+
+import (
+	"context"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// BalanceChangedMsg is the message balance changes are logged with.
+const BalanceChangedMsg = "Balance changed"
+
+// BalanceChange is a change of a watched balance between two blocks.
+type BalanceChange struct {
+	Address common.Address `json:"address"`
+	Block   *big.Int       `json:"block"`
+	Old     *big.Int       `json:"oldBalance"`
+	New     *big.Int       `json:"newBalance"`
+}
+
+// Delta is New minus Old, negative for a drop.
+func (c BalanceChange) Delta() *big.Int {
+	return new(big.Int).Sub(c.New, c.Old)
+}
+
+// BalanceHandler is a Handler also told of the balance changes found by
+// WatchBalances, such as an alert on a drained wallet.
+type BalanceHandler interface {
+	Handler
+	HandleBalance(ctx context.Context, c BalanceChange) error
+}
+
+// HandleBalance hands c to h if it is a BalanceHandler, the others only
+// act on txs.
+func HandleBalance(ctx context.Context, h Handler, c BalanceChange) error {
+	if b, ok := h.(BalanceHandler); ok {
+		return b.HandleBalance(ctx, c)
+	}
+	return nil
+}
+
+// balanceTracker remembers the last balance of every address.
+type balanceTracker struct {
+	threshold *big.Int
+	last      map[common.Address]*big.Int
+}
+
+func newBalanceTracker(threshold *big.Int) *balanceTracker {
+	return &balanceTracker{threshold: threshold, last: make(map[common.Address]*big.Int)}
+}
+
+// update compares the balance of addr at block with the last reported one
+// and reports a change by more than the threshold. The first balance of an
+// address is only recorded. A change within the threshold is not recorded
+// either, so that small changes add up until they are reported.
+func (t *balanceTracker) update(addr common.Address, block, balance *big.Int) (BalanceChange, bool) {
+	old, ok := t.last[addr]
+	if !ok {
+		t.last[addr] = balance
+		return BalanceChange{}, false
+	}
+
+	c := BalanceChange{Address: addr, Block: block, Old: old, New: balance}
+	if delta := new(big.Int).Abs(c.Delta()); delta.Sign() == 0 || (t.threshold != nil && delta.Cmp(t.threshold) <= 0) {
+		return BalanceChange{}, false
+	}
+	t.last[addr] = balance
+	return c, true
+}
+
+// WatchBalances fetches the balance of addrs at every new block head and
+// hands every change by more than threshold wei to handler, nil threshold
+// meaning any change. This catches what pending txs don't show, such as
+// internal transfers. It needs a subscription, so a ws or IPC endpoint, and
+// follows the connection of Run when that reconnects. It returns once ctx
+// is done.
+func (m *Monitor) WatchBalances(ctx context.Context, addrs []common.Address, threshold *big.Int, handler func(BalanceChange)) error {
+	tracker := newBalanceTracker(threshold)
+	m.checkBalances(ctx, addrs, nil, tracker, handler)
+
+	heads := make(chan *types.Header, 16)
+	sub, err := m.rpcClient().EthSubscribe(ctx, heads, "newHeads")
+	if err != nil {
+		return err
+	}
+
+	maxBackoff := m.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+	backoff := min(minBackoff, maxBackoff)
+
+	for {
+		select {
+		case <-ctx.Done():
+			sub.Unsubscribe()
+			return nil
+
+		case h := <-heads:
+			backoff = min(minBackoff, maxBackoff)
+			m.head.set(h)
+			m.checkBalances(ctx, addrs, h.Number, tracker, handler)
+
+		case err := <-sub.Err():
+			slog.Warn("Balance head subscription dropped, resubscribing", "err", err)
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-time.After(jitter(backoff)):
+				}
+				if sub, err = m.rpcClient().EthSubscribe(ctx, heads, "newHeads"); err == nil {
+					break
+				}
+				slog.Warn("Resubscribe to heads failed", "err", err)
+				backoff = min(backoff*2, maxBackoff)
+			}
+		}
+	}
+}
+
+// checkBalances fetches the balances of addrs at block, nil being the
+// latest, and logs and hands over their changes.
+func (m *Monitor) checkBalances(ctx context.Context, addrs []common.Address, block *big.Int, tracker *balanceTracker, handler func(BalanceChange)) {
+	client := m.Client()
+	for _, addr := range addrs {
+		balance, err := client.BalanceAt(ctx, addr, block)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("Fetch balance failed", "address", addr, "block", block, "err", err)
+			}
+			continue
+		}
+
+		c, ok := tracker.update(addr, block, balance)
+		if !ok {
+			continue
+		}
+		slog.Info(BalanceChangedMsg, "address", m.Labels.Name(addr), "block", block,
+			"old", FormatEther(c.Old), "new", FormatEther(c.New), "delta", FormatEther(c.Delta()))
+		handler(c)
+	}
+}
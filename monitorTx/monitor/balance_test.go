@@ -0,0 +1,40 @@
+package monitor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestBalanceTracker(t *testing.T) {
+	addr := common.Address{1}
+	tracker := newBalanceTracker(big.NewInt(10))
+
+	steps := []struct {
+		balance int64
+		changed bool
+	}{
+		{100, false}, // the first balance is the reference
+		{95, false},  // within the threshold
+		{89, true},   // 11 below the reference, adding up the small drops
+		{89, false},
+		{100, true},
+	}
+	for i, s := range steps {
+		c, ok := tracker.update(addr, big.NewInt(int64(i)), big.NewInt(s.balance))
+		if ok != s.changed {
+			t.Fatalf("step %d: balance %d changed %v, want %v", i, s.balance, ok, s.changed)
+		}
+		if ok && c.New.Int64() != s.balance {
+			t.Errorf("step %d: change to %v, want %d", i, c.New, s.balance)
+		}
+	}
+
+	// without a threshold any change counts
+	anyChange := newBalanceTracker(nil)
+	anyChange.update(addr, nil, big.NewInt(1))
+	if c, ok := anyChange.update(addr, nil, big.NewInt(2)); !ok || c.Delta().Int64() != 1 {
+		t.Errorf("change of 1 wei: %+v, %v", c, ok)
+	}
+}
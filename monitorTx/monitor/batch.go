@@ -0,0 +1,62 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxBatchSize bounds the hashes fetched by one batch call, a fuller batch
+// is sent before its BatchWindow is over.
+const maxBatchSize = 100
+
+// fetchBatch fetches the txs of hashes in a single JSON-RPC batch call on
+// the current connection and returns those found with their raw JSON. The
+// elements fail on their own, a failed one is skipped like an unknown hash.
+func (m *Monitor) fetchBatch(ctx context.Context, hashes []common.Hash) ([]fetchedTx, error) {
+	raws := make([]json.RawMessage, len(hashes))
+	elems := make([]rpc.BatchElem, len(hashes))
+	for i, h := range hashes {
+		elems[i] = rpc.BatchElem{
+			Method: "eth_getTransactionByHash",
+			Args:   []interface{}{h},
+			Result: &raws[i],
+		}
+	}
+
+	if m.FetchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.FetchTimeout)
+		defer cancel()
+	}
+	if err := m.rpcClient().BatchCallContext(ctx, elems); err != nil {
+		return nil, err
+	}
+
+	var found []fetchedTx
+	for i, elem := range elems {
+		err := elem.Error
+		var tx *types.Transaction
+		if err == nil {
+			tx, err = decodeRawTx(raws[i])
+		}
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			slog.Debug("Batched fetch failed", "hash", hashes[i], "err", err)
+			fetchErrors.Inc()
+			observeRPCError(CallFetch, err)
+			continue
+		}
+		txsFetched.Inc()
+		found = append(found, fetchedTx{tx: tx, raw: raws[i]})
+	}
+	return found, nil
+}
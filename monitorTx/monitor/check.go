@@ -0,0 +1,99 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultCheckTimeout bounds every step of CheckEndpoint.
+const DefaultCheckTimeout = 5 * time.Second
+
+// CheckStep is one step of CheckEndpoint, it passed if Err is nil.
+type CheckStep struct {
+	Name   string
+	Result string
+	Err    error
+}
+
+// CheckEndpoint verifies that url can be watched: it dials it, reads the
+// chain ID and head block and, unless url is polled, subscribes to pending
+// txs for a moment. Every step is bounded by timeout, the steps after a
+// failed dial are left out. header is sent as the one of an EndpointPool.
+func CheckEndpoint(ctx context.Context, url string, header http.Header, timeout time.Duration) []CheckStep {
+	var steps []CheckStep
+
+	dctx, cancel := context.WithTimeout(ctx, timeout)
+	rpccli, err := dial(dctx, url, header)
+	cancel()
+	if err != nil {
+		return append(steps, CheckStep{Name: "dial", Err: err})
+	}
+	defer rpccli.Close()
+	steps = append(steps, CheckStep{Name: "dial", Result: "ok"})
+	client := ethclient.NewClient(rpccli)
+
+	cctx, cancel := context.WithTimeout(ctx, timeout)
+	chainID, err := client.ChainID(cctx)
+	cancel()
+	step := CheckStep{Name: "chain ID", Err: err}
+	if err == nil {
+		step.Result = chainID.String()
+	}
+	steps = append(steps, step)
+
+	bctx, cancel := context.WithTimeout(ctx, timeout)
+	head, err := client.BlockNumber(bctx)
+	cancel()
+	step = CheckStep{Name: "block number", Err: err}
+	if err == nil {
+		step.Result = fmt.Sprint(head)
+	}
+	steps = append(steps, step)
+
+	if isHTTP(url) {
+		return append(steps, CheckStep{Name: "pending txs", Result: "polled over http, no subscription"})
+	}
+	return append(steps, checkSubscription(ctx, rpccli, timeout))
+}
+
+// checkSubscription subscribes to pending txs and waits up to timeout for
+// the first hash. A quiet but working subscription still passes.
+func checkSubscription(ctx context.Context, rpccli *rpc.Client, timeout time.Duration) CheckStep {
+	step := CheckStep{Name: "pending txs"}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ch := make(chan string, 16)
+	sub, err := rpccli.EthSubscribe(ctx, ch, "newPendingTransactions")
+	if err != nil {
+		step.Err = err
+		return step
+	}
+	defer sub.Unsubscribe()
+
+	select {
+	case h := <-ch:
+		step.Result = "subscribed, got " + h
+	case err := <-sub.Err():
+		step.Err = err
+	case <-ctx.Done():
+		step.Result = fmt.Sprintf("subscribed, no pending tx within %v", timeout)
+	}
+	return step
+}
+
+// CheckPassed reports whether every step passed.
+func CheckPassed(steps []CheckStep) bool {
+	for _, s := range steps {
+		if s.Err != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,45 @@
+package monitor
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestCheckEndpoint(t *testing.T) {
+	tx := types.NewTransaction(1, common.Address{1}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	path := serveIPC(t, &ipcNode{chainID: big.NewInt(1337), tx: tx})
+
+	steps := CheckEndpoint(context.Background(), path, nil, time.Second)
+	if !CheckPassed(steps) {
+		t.Fatalf("check failed: %+v", steps)
+	}
+	want := []string{"ok", "1337", "100", "subscribed, got " + tx.Hash().Hex()}
+	if len(steps) != len(want) {
+		t.Fatalf("%d steps, want %d", len(steps), len(want))
+	}
+	for i, s := range steps {
+		if s.Result != want[i] {
+			t.Errorf("%s: got %q, want %q", s.Name, s.Result, want[i])
+		}
+	}
+}
+
+func TestCheckEndpointUnreachable(t *testing.T) {
+	start := time.Now()
+	steps := CheckEndpoint(context.Background(), filepath.Join(t.TempDir(), "missing.ipc"), nil, time.Second)
+	if CheckPassed(steps) {
+		t.Fatalf("check of a missing socket passed: %+v", steps)
+	}
+	if len(steps) != 1 || steps[0].Name != "dial" {
+		t.Errorf("got %+v, want a failed dial only", steps)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("check took %v", elapsed)
+	}
+}
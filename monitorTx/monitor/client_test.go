@@ -0,0 +1,146 @@
+package monitor
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// fakeClient is a TxClient serving blocks and pending txs from memory and
+// recording the txs sent to it.
+type fakeClient struct {
+	mu sync.Mutex
+
+	chainID  *big.Int
+	nonce    uint64
+	gasPrice *big.Int
+	tip      *big.Int
+	baseFee  *big.Int
+	balance  *big.Int
+	blocks   []*types.Block
+	pending  map[common.Hash]*types.Transaction
+	receipts map[common.Hash]*types.Receipt
+	sendErr  error
+	callErr  error
+	// calls answer the calls by selector
+	calls map[[4]byte][]byte
+	// sendErrs fail the next sends in turn, before sendErr applies
+	sendErrs []error
+
+	sent []*types.Transaction
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		chainID:  big.NewInt(1),
+		gasPrice: big.NewInt(gwei(10)),
+		tip:      big.NewInt(gwei(1)),
+		baseFee:  big.NewInt(gwei(20)),
+		balance:  big.NewInt(params.Ether),
+		pending:  make(map[common.Hash]*types.Transaction),
+	}
+}
+
+// gwei converts n gwei to wei.
+func gwei(n int64) int64 { return n * 1e9 }
+
+func (c *fakeClient) ChainID(ctx context.Context) (*big.Int, error) {
+	return c.chainID, nil
+}
+
+func (c *fakeClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return uint64(len(c.blocks) - 1), nil
+}
+
+func (c *fakeClient) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	if number == nil {
+		return c.blocks[len(c.blocks)-1], nil
+	}
+	if n := number.Uint64(); n < uint64(len(c.blocks)) {
+		return c.blocks[n], nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (c *fakeClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(int64(len(c.blocks))), BaseFee: c.baseFee}, nil
+}
+
+func (c *fakeClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	if tx, ok := c.pending[hash]; ok {
+		return tx, true, nil
+	}
+	return nil, false, ethereum.NotFound
+}
+
+func (c *fakeClient) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	if r, ok := c.receipts[hash]; ok {
+		return r, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (c *fakeClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return c.balance, nil
+}
+
+func (c *fakeClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.nonce, nil
+}
+
+func (c *fakeClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return c.NonceAt(ctx, account, nil)
+}
+
+func (c *fakeClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return 21000, nil
+}
+
+func (c *fakeClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if len(msg.Data) >= 4 && c.callErr == nil {
+		if out, ok := c.calls[[4]byte(msg.Data)]; ok {
+			return out, nil
+		}
+	}
+	return nil, c.callErr
+}
+
+func (c *fakeClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return c.gasPrice, nil
+}
+
+func (c *fakeClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return c.tip, nil
+}
+
+func (c *fakeClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(c.sendErrs) > 0 {
+		err := c.sendErrs[0]
+		c.sendErrs = c.sendErrs[1:]
+		if err != nil {
+			return err
+		}
+	}
+	if c.sendErr != nil {
+		return c.sendErr
+	}
+	c.sent = append(c.sent, tx)
+	return nil
+}
+
+// block returns a block numbered n holding txs.
+func block(n int64, txs ...*types.Transaction) *types.Block {
+	return types.NewBlockWithHeader(&types.Header{Number: big.NewInt(n)}).WithBody(types.Body{Transactions: txs})
+}
@@ -0,0 +1,110 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BatchHandler is a Handler also taking several matches at once, such as
+// an alert summing up a burst of txs in one message.
+type BatchHandler interface {
+	Handler
+	HandleBatch(ctx context.Context, ms []Match) error
+}
+
+// BatchHandlerFunc adapts a func to a BatchHandler, a single match is
+// handed over as a batch of one.
+type BatchHandlerFunc func(ctx context.Context, ms []Match) error
+
+func (f BatchHandlerFunc) Handle(ctx context.Context, m Match) error {
+	return f(ctx, []Match{m})
+}
+
+func (f BatchHandlerFunc) HandleBatch(ctx context.Context, ms []Match) error {
+	return f(ctx, ms)
+}
+
+// HandleMatches hands ms to h as one batch if it is a BatchHandler, or else
+// one by one, returning the last error.
+func HandleMatches(ctx context.Context, h Handler, ms []Match) error {
+	if b, ok := h.(BatchHandler); ok {
+		return b.HandleBatch(ctx, ms)
+	}
+	var failed error
+	for _, m := range ms {
+		if err := h.Handle(ctx, m); err != nil {
+			failed = err
+		}
+	}
+	return failed
+}
+
+// Coalescer is a Handler collecting the matches of an address for Window
+// after the first one and handing them to Next at once, see HandleMatches.
+// Handle waits for the batch of its match and returns its result, a batch
+// is handed over early once ctx is done.
+type Coalescer struct {
+	Window time.Duration
+	Next   Handler
+
+	// Key is the address a match is batched by, its sender if nil.
+	Key func(Match) common.Address
+
+	mu      sync.Mutex
+	pending map[common.Address]*batch
+}
+
+// batch is the matches of an address waiting for the end of the window.
+type batch struct {
+	matches []Match
+	once    sync.Once
+	done    chan struct{}
+	err     error
+}
+
+// Handle adds m to the batch of its address, starting one if there is none.
+func (c *Coalescer) Handle(ctx context.Context, m Match) error {
+	key := m.Record.From
+	if c.Key != nil {
+		key = c.Key(m)
+	}
+
+	c.mu.Lock()
+	if c.pending == nil {
+		c.pending = make(map[common.Address]*batch)
+	}
+	b, ok := c.pending[key]
+	if !ok {
+		b = &batch{done: make(chan struct{})}
+		c.pending[key] = b
+		time.AfterFunc(c.Window, func() { c.flush(ctx, key, b) })
+	}
+	b.matches = append(b.matches, m)
+	c.mu.Unlock()
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		// rather than losing the batch on shutdown
+		c.flush(ctx, key, b)
+	}
+	return b.err
+}
+
+// flush hands b over to Next, once, and waits for it.
+func (c *Coalescer) flush(ctx context.Context, key common.Address, b *batch) {
+	b.once.Do(func() {
+		c.mu.Lock()
+		if c.pending[key] == b {
+			delete(c.pending, key)
+		}
+		c.mu.Unlock()
+
+		b.err = HandleMatches(ctx, c.Next, b.matches)
+		close(b.done)
+	})
+	<-b.done
+}
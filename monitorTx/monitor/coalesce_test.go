@@ -0,0 +1,85 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// matchFrom is a match of a tx sent by from.
+func matchFrom(from common.Address, nonce uint64) Match {
+	tx := valueTx(int64(nonce), nil)
+	return Match{Tx: tx, Record: NewTxRecord(tx, from)}
+}
+
+func TestCoalescer(t *testing.T) {
+	a, b := common.Address{0xa}, common.Address{0xb}
+
+	var mu sync.Mutex
+	batches := make(map[common.Address][]int)
+	var calls int
+	c := &Coalescer{Window: 50 * time.Millisecond, Next: BatchHandlerFunc(func(ctx context.Context, ms []Match) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		batches[ms[0].Record.From] = append(batches[ms[0].Record.From], len(ms))
+		return nil
+	})}
+
+	var wg sync.WaitGroup
+	for i, from := range []common.Address{a, a, b, a} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.Handle(context.Background(), matchFrom(from, uint64(i))); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls != 2 || len(batches[a]) != 1 || batches[a][0] != 3 || len(batches[b]) != 1 || batches[b][0] != 1 {
+		t.Errorf("got %d calls, batches %v", calls, batches)
+	}
+
+	// the next match starts a new window
+	if err := c.Handle(context.Background(), matchFrom(a, 9)); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Errorf("got %d calls after the window, want 3", calls)
+	}
+}
+
+func TestCoalescerFallback(t *testing.T) {
+	var mu sync.Mutex
+	var handled int
+	c := &Coalescer{Window: time.Hour, Next: HandlerFunc(func(ctx context.Context, m Match) error {
+		mu.Lock()
+		defer mu.Unlock()
+		handled++
+		return nil
+	})}
+
+	// a cancelled ctx hands the batch over without waiting out the window
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for i := range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Handle(ctx, matchFrom(common.Address{0xa}, uint64(i)))
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	wg.Wait()
+
+	// a plain Handler gets the batch one by one
+	if handled != 2 {
+		t.Errorf("handled %d matches, want 2", handled)
+	}
+}
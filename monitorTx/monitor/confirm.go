@@ -0,0 +1,88 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultConfirmTimeout is how long a matched tx may stay unmined before
+// Confirmed takes it for dropped.
+const DefaultConfirmTimeout = 30 * time.Minute
+
+var ErrDropped = errors.New("tx not mined before the timeout")
+
+// Confirmed is a Handler waiting for the matched tx to be mined and buried
+// under Confirmations blocks, counting its own, before handing the match
+// with its receipt on to Next. This runs in the handler goroutine of the
+// match, so Monitor shutdown is bounded by its DrainTimeout.
+type Confirmed struct {
+	Confirmations uint64
+
+	// Timeout is how long the tx may stay unmined, it fails with
+	// ErrDropped after that. Zero waits until ctx is done.
+	Timeout time.Duration
+
+	// PollInterval paces the receipt and head lookups, defaulting to
+	// the one of sent responses.
+	PollInterval time.Duration
+
+	Next Handler
+}
+
+// Handle waits for the confirmations of m.Tx and runs Next.
+func (c *Confirmed) Handle(ctx context.Context, m Match) error {
+	receipt, err := c.wait(ctx, m.Client, m.Tx)
+	if err != nil {
+		return err
+	}
+	m.Receipt = receipt
+	return c.Next.Handle(ctx, m)
+}
+
+// wait polls the receipt of tx until it is Confirmations deep. The receipt
+// is looked up again every time, a reorg may move or drop the tx.
+func (c *Confirmed) wait(ctx context.Context, client TxClient, tx *types.Transaction) (*types.Receipt, error) {
+	interval := c.PollInterval
+	if interval <= 0 {
+		interval = receiptPollInterval
+	}
+	deadline := time.Now().Add(c.Timeout)
+
+	for {
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		switch {
+		case err == nil:
+			head, err := client.BlockNumber(ctx)
+			if err != nil {
+				slog.Debug("Head lookup failed", "err", err)
+				break
+			}
+			mined := receipt.BlockNumber.Uint64()
+			if head >= mined && head-mined+1 >= c.Confirmations {
+				slog.Info("Tx confirmed", "hash", tx.Hash(), "block", mined,
+					"confirmations", head-mined+1, "status", receipt.Status)
+				return receipt, nil
+			}
+			// mined, only the depth is left to wait for
+			deadline = time.Now().Add(c.Timeout)
+		case errors.Is(err, ethereum.NotFound):
+			if c.Timeout > 0 && time.Now().After(deadline) {
+				slog.Warn("Tx not mined, dropped", "hash", tx.Hash(), "timeout", c.Timeout)
+				return nil, ErrDropped
+			}
+		default:
+			slog.Debug("Receipt lookup failed", "hash", tx.Hash(), "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestConfirmed(t *testing.T) {
+	tx := valueTx(0, nil)
+
+	client := newFakeClient()
+	client.blocks = []*types.Block{block(0), block(1), block(2)}
+	client.receipts = map[common.Hash]*types.Receipt{
+		tx.Hash(): {Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(1)},
+	}
+
+	var got *types.Receipt
+	next := HandlerFunc(func(ctx context.Context, m Match) error {
+		got = m.Receipt
+		return nil
+	})
+
+	// mined in block 1 with head 2, two confirmations
+	c := &Confirmed{Confirmations: 2, PollInterval: time.Millisecond, Next: next}
+	if err := c.Handle(context.Background(), Match{Tx: tx, Client: client}); err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.BlockNumber.Int64() != 1 {
+		t.Fatalf("next got receipt %v", got)
+	}
+
+	// a third one never comes
+	got = nil
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	c.Confirmations = 3
+	if err := c.Handle(ctx, Match{Tx: tx, Client: client}); !errors.Is(err, context.DeadlineExceeded) || got != nil {
+		t.Errorf("got %v, next ran %v", err, got != nil)
+	}
+}
+
+func TestConfirmedDropped(t *testing.T) {
+	client := newFakeClient()
+	client.blocks = []*types.Block{block(0)}
+
+	ran := false
+	c := &Confirmed{
+		Confirmations: 1,
+		Timeout:       10 * time.Millisecond,
+		PollInterval:  time.Millisecond,
+		Next:          HandlerFunc(func(context.Context, Match) error { ran = true; return nil }),
+	}
+	err := c.Handle(context.Background(), Match{Tx: valueTx(0, nil), Client: client})
+	if !errors.Is(err, ErrDropped) || ran {
+		t.Errorf("got %v, next ran %v, want ErrDropped", err, ran)
+	}
+}
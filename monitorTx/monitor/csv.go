@@ -0,0 +1,76 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// csvHeader names the columns written by CSVWriter.
+var csvHeader = []string{"hash", "from", "to", "valueWei", "valueEth", "gas", "gasPrice", "nonce", "timestamp"}
+
+// CSVHeader returns the header row of CSVWriter, for output files that
+// start over, see RotatingFile.
+func CSVHeader() []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(csvHeader)
+	w.Flush()
+	return buf.Bytes()
+}
+
+// CSVWriter writes TxRecords as CSV lines, after a header row unless told
+// otherwise. Every record is flushed at once so the output is usable while
+// the monitor runs.
+type CSVWriter struct {
+	mu     sync.Mutex
+	w      *csv.Writer
+	header bool
+}
+
+// NewCSVWriter writes to w, header is false when appending to earlier output.
+func NewCSVWriter(w io.Writer, header bool) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w), header: header}
+}
+
+// Write writes rec, matched at t, preceded by the header on the first call.
+func (c *CSVWriter) Write(rec *TxRecord, t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.header {
+		if err := c.w.Write(csvHeader); err != nil {
+			return err
+		}
+		c.header = false
+	}
+
+	var to, price string
+	if rec.To != nil {
+		to = rec.To.Hex()
+	}
+	if rec.GasPrice != nil {
+		price = rec.GasPrice.String()
+	}
+
+	err := c.w.Write([]string{
+		rec.Hash.Hex(),
+		rec.From.Hex(),
+		to,
+		rec.Value.String(),
+		FormatEther(rec.Value),
+		strconv.FormatUint(rec.Gas, 10),
+		price,
+		strconv.FormatUint(rec.Nonce, 10),
+		t.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	c.w.Flush()
+	return c.w.Error()
+}
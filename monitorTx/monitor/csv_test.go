@@ -0,0 +1,42 @@
+package monitor
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf, true)
+
+	to := common.HexToAddress("0x003be5Df5FeF651EF0C59cD175c73ca1415f53eA")
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	recs := []*TxRecord{
+		{To: &to, Value: big.NewInt(params.Ether * 3 / 2), Gas: 21000, GasPrice: big.NewInt(7), Nonce: 1},
+		{Value: big.NewInt(0), Gas: 53000, Nonce: 2},
+	}
+	for _, rec := range recs {
+		if err := w.Write(rec, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	zero := common.Hash{}.Hex()
+	from := common.Address{}.Hex()
+	want := strings.Join([]string{
+		"hash,from,to,valueWei,valueEth,gas,gasPrice,nonce,timestamp",
+		zero + "," + from + "," + to.Hex() + ",1500000000000000000,1.5,21000,7,1,2024-01-02T03:04:05Z",
+		zero + "," + from + ",,0,0,53000,,2,2024-01-02T03:04:05Z",
+	}, "\n") + "\n"
+
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,38 @@
+package monitor
+
+import "github.com/ethereum/go-ethereum/common"
+
+// DefaultDedupSize is the number of recent pending hashes remembered.
+const DefaultDedupSize = 4096
+
+// hashRing is a fixed size set of the most recently added hashes, the
+// oldest one is evicted once it is full. It is not safe for concurrent use.
+type hashRing struct {
+	ring []common.Hash
+	next int
+	set  map[common.Hash]struct{}
+}
+
+func newHashRing(size int) *hashRing {
+	return &hashRing{
+		ring: make([]common.Hash, 0, size),
+		set:  make(map[common.Hash]struct{}, size),
+	}
+}
+
+// add records h and reports whether it was new.
+func (r *hashRing) add(h common.Hash) bool {
+	if _, ok := r.set[h]; ok {
+		return false
+	}
+
+	if len(r.ring) < cap(r.ring) {
+		r.ring = append(r.ring, h)
+	} else {
+		delete(r.set, r.ring[r.next])
+		r.ring[r.next] = h
+		r.next = (r.next + 1) % len(r.ring)
+	}
+	r.set[h] = struct{}{}
+	return true
+}
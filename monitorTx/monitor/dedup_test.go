@@ -0,0 +1,30 @@
+package monitor
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestHashRing(t *testing.T) {
+	r := newHashRing(2)
+	a, b, c := common.Hash{1}, common.Hash{2}, common.Hash{3}
+
+	if !r.add(a) || !r.add(b) {
+		t.Fatal("new hashes reported as seen")
+	}
+	if r.add(a) {
+		t.Error("duplicate reported as new")
+	}
+
+	// c evicts a, the oldest
+	if !r.add(c) {
+		t.Error("c reported as seen")
+	}
+	if !r.add(a) {
+		t.Error("evicted hash still remembered")
+	}
+	if r.add(c) {
+		t.Error("c forgotten")
+	}
+}
@@ -0,0 +1,200 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Rotation policies of an EndpointPool.
+const (
+	// PolicyFailover uses the primary endpoint for everything and only
+	// moves to the next one when it keeps failing.
+	PolicyFailover = "failover"
+
+	// PolicyRoundRobin also spreads the tx fetches over every healthy
+	// endpoint.
+	PolicyRoundRobin = "round-robin"
+)
+
+const (
+	// failoverAfter failed reconnect attempts move to the next endpoint.
+	failoverAfter = 2
+
+	// maxFetchFailures in a row take an endpoint out of the fetch rotation
+	// for endpointCooldown.
+	maxFetchFailures = 3
+	endpointCooldown = 30 * time.Second
+)
+
+type endpoint struct {
+	url       string
+	rpc       *rpc.Client
+	client    TxClient
+	failures  int
+	downUntil time.Time
+}
+
+// EndpointPool holds the endpoints of a Monitor. The primary one carries
+// the subscription, the others take over when it fails and, with
+// PolicyRoundRobin, share the tx fetches.
+type EndpointPool struct {
+	Policy string
+
+	// Header is sent with every request to the http and ws endpoints, such
+	// as the auth header of a private gateway.
+	Header http.Header
+
+	mu        sync.Mutex
+	endpoints []*endpoint
+	primary   int
+	cursor    int
+}
+
+// NewEndpointPool returns a pool of urls, the first being the primary.
+func NewEndpointPool(urls []string, policy string) (*EndpointPool, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("no endpoint")
+	}
+	switch policy {
+	case "":
+		policy = PolicyFailover
+	case PolicyFailover, PolicyRoundRobin:
+	default:
+		return nil, fmt.Errorf("unknown endpoint policy %q", policy)
+	}
+
+	p := &EndpointPool{Policy: policy}
+	for _, url := range urls {
+		p.endpoints = append(p.endpoints, &endpoint{url: url})
+	}
+	return p, nil
+}
+
+// Primary returns the url of the primary endpoint.
+func (p *EndpointPool) Primary() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.endpoints[p.primary].url
+}
+
+// Rotate makes the next endpoint primary and returns its url.
+func (p *EndpointPool) Rotate() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.primary = (p.primary + 1) % len(p.endpoints)
+	return p.endpoints[p.primary].url
+}
+
+// DialFetchers connects to the secondary endpoints used by round-robin
+// fetches. Endpoints failing to connect are left out.
+func (p *EndpointPool) DialFetchers(ctx context.Context) {
+	if p.Policy != PolicyRoundRobin {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, e := range p.endpoints {
+		if i == p.primary || e.rpc != nil {
+			continue
+		}
+		rpccli, err := dial(ctx, e.url, p.Header)
+		if err != nil {
+			slog.Warn("Endpoint left out of fetches", "endpoint", e.url, "err", err)
+			continue
+		}
+		e.rpc, e.client = rpccli, ethclient.NewClient(rpccli)
+	}
+}
+
+// dial connects to url sending header, IPC paths ignore it.
+func dial(ctx context.Context, url string, header http.Header) (*rpc.Client, error) {
+	return rpc.DialOptions(ctx, url, rpc.WithHeaders(header))
+}
+
+// ParseHeaders parses "Name: value" headers, a name may repeat.
+func ParseHeaders(list []string) (http.Header, error) {
+	header := make(http.Header)
+	for _, s := range list {
+		name, value, ok := strings.Cut(s, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid header %q, want \"Name: value\"", s)
+		}
+		header.Add(textproto.CanonicalMIMEHeaderKey(name), strings.TrimSpace(value))
+	}
+	return header, nil
+}
+
+// fetcher returns the client for the next tx fetch, primary being the
+// client of the primary endpoint, and a func reporting the fetch result.
+func (p *EndpointPool) fetcher(primary TxClient) (TxClient, func(error)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Policy != PolicyRoundRobin {
+		return primary, func(error) {}
+	}
+
+	now := time.Now()
+	for range p.endpoints {
+		i := p.cursor
+		p.cursor = (p.cursor + 1) % len(p.endpoints)
+
+		e := p.endpoints[i]
+		if now.Before(e.downUntil) {
+			continue
+		}
+		client := e.client
+		if i == p.primary {
+			client = primary
+		}
+		if client == nil {
+			continue
+		}
+		return client, func(err error) { p.report(e, err) }
+	}
+	return primary, func(error) {}
+}
+
+// report tracks the health of e from the result of a fetch.
+func (p *EndpointPool) report(e *endpoint, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// a tx dropped from the pool says nothing about the endpoint
+	if err == nil || errors.Is(err, ethereum.NotFound) {
+		e.failures = 0
+		return
+	}
+
+	if e.failures++; e.failures >= maxFetchFailures {
+		slog.Warn("Endpoint keeps failing fetches, resting it", "endpoint", e.url, "failures", e.failures, "cooldown", endpointCooldown)
+		e.failures = 0
+		e.downUntil = time.Now().Add(endpointCooldown)
+	}
+}
+
+// Close disconnects the secondary endpoints.
+func (p *EndpointPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, e := range p.endpoints {
+		if e.rpc != nil {
+			e.rpc.Close()
+			e.rpc, e.client = nil, nil
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package monitor
+
+import (
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestEndpointPoolRotate(t *testing.T) {
+	if _, err := NewEndpointPool(nil, PolicyFailover); err == nil {
+		t.Error("empty pool accepted")
+	}
+	if _, err := NewEndpointPool([]string{"a"}, "random"); err == nil {
+		t.Error("unknown policy accepted")
+	}
+
+	p, err := NewEndpointPool([]string{"a", "b", "c"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Policy != PolicyFailover || p.Primary() != "a" {
+		t.Fatalf("got policy %s primary %s", p.Policy, p.Primary())
+	}
+	for _, want := range []string{"b", "c", "a"} {
+		if got := p.Rotate(); got != want {
+			t.Errorf("rotated to %s, want %s", got, want)
+		}
+	}
+}
+
+func TestEndpointPoolFetcher(t *testing.T) {
+	primary, b, c := newFakeClient(), newFakeClient(), newFakeClient()
+
+	failover, _ := NewEndpointPool([]string{"a", "b"}, PolicyFailover)
+	failover.endpoints[1].client = b
+	for i := 0; i < 3; i++ {
+		if got, _ := failover.fetcher(primary); got != primary {
+			t.Fatal("failover fetched from a secondary endpoint")
+		}
+	}
+
+	p, _ := NewEndpointPool([]string{"a", "b", "c"}, PolicyRoundRobin)
+	p.endpoints[1].client = b
+	p.endpoints[2].client = c
+
+	var order []TxClient
+	for i := 0; i < 3; i++ {
+		client, report := p.fetcher(primary)
+		order = append(order, client)
+		report(nil)
+	}
+	if order[0] != primary || order[1] != b || order[2] != c {
+		t.Errorf("fetches not spread round-robin")
+	}
+
+	// dropped txs are fine, errors rest the endpoint
+	for i := 0; i < maxFetchFailures; i++ {
+		_, report := p.fetcher(primary)
+		report(ethereum.NotFound)
+		_, report = p.fetcher(primary)
+		report(errors.New("connection reset"))
+		p.fetcher(primary)
+	}
+	for i := 0; i < 4; i++ {
+		if client, _ := p.fetcher(primary); client == b {
+			t.Fatal("fetched from a resting endpoint")
+		}
+	}
+}
+
+func TestNewMonitorPoolFailover(t *testing.T) {
+	tx := types.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil)
+	good := serveIPC(t, &ipcNode{chainID: big.NewInt(7), tx: tx})
+
+	pool, _ := NewEndpointPool([]string{good + ".missing", good}, PolicyFailover)
+	m, err := NewMonitorPool(pool, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	if m.URL != good || pool.Primary() != good || m.ChainID.Int64() != 7 {
+		t.Errorf("connected to %s, primary %s, chain ID %v", m.URL, pool.Primary(), m.ChainID)
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	header, err := ParseHeaders([]string{"authorization: Bearer X", "X-Tag: a, b", "X-Tag:c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := header.Get("Authorization"); got != "Bearer X" {
+		t.Errorf("Authorization: got %q", got)
+	}
+	if got := header.Values("X-Tag"); len(got) != 2 || got[0] != "a, b" || got[1] != "c" {
+		t.Errorf("X-Tag: got %q", got)
+	}
+
+	for _, bad := range []string{"Bearer X", ": x", "X Tag: x"} {
+		if _, err := ParseHeaders([]string{bad}); err == nil {
+			t.Errorf("%q accepted", bad)
+		}
+	}
+}
+
+func TestDialHeaderIPv6(t *testing.T) {
+	l, err := net.Listen("tcp", "[::1]:0")
+	if err != nil {
+		t.Skip("no IPv6 loopback:", err)
+	}
+	server := rpc.NewServer()
+	t.Cleanup(server.Stop)
+	if err := server.RegisterName("eth", &ipcNode{chainID: big.NewInt(1337)}); err != nil {
+		t.Fatal(err)
+	}
+	// an auth gateway in front of the node
+	go http.Serve(l, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer X" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		server.ServeHTTP(w, r)
+	}))
+	t.Cleanup(func() { l.Close() })
+
+	url := "http://" + l.Addr().String()
+	pool, err := NewEndpointPool([]string{url}, PolicyFailover)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewMonitorPool(pool, nil); err == nil {
+		t.Fatal("connected without the auth header")
+	}
+
+	pool.Header = http.Header{"Authorization": {"Bearer X"}}
+	m, err := NewMonitorPool(pool, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if m.ChainID.Int64() != 1337 {
+		t.Errorf("chain ID %v over %s", m.ChainID, url)
+	}
+}
@@ -0,0 +1,63 @@
+package monitor
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const erc20JSON = `[
+	{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}]},
+	{"type":"function","name":"transferFrom","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"}]}
+]`
+
+var erc20ABI abi.ABI
+
+func init() {
+	var err error
+	if erc20ABI, err = abi.JSON(strings.NewReader(erc20JSON)); err != nil {
+		panic(err)
+	}
+}
+
+// TokenTransfer is an ERC-20 transfer decoded from calldata.
+type TokenTransfer struct {
+	Method string `json:"method"`
+	// From is only set by transferFrom, transfer moves the sender's tokens.
+	From   *common.Address `json:"from,omitempty"`
+	To     common.Address  `json:"to"`
+	Amount *big.Int        `json:"amount"`
+}
+
+// DecodeTokenTransfer decodes an ERC-20 transfer or transferFrom call.
+// It returns false for any other calldata.
+func DecodeTokenTransfer(data []byte) (*TokenTransfer, bool) {
+	if len(data) < SelectorLength {
+		return nil, false
+	}
+
+	method, err := erc20ABI.MethodById(data[:SelectorLength])
+	if err != nil {
+		return nil, false
+	}
+
+	args := make(map[string]interface{})
+	if err := method.Inputs.UnpackIntoMap(args, data[SelectorLength:]); err != nil {
+		return nil, false
+	}
+
+	t := &TokenTransfer{Method: method.Name}
+	var ok bool
+	if t.To, ok = args["to"].(common.Address); !ok {
+		return nil, false
+	}
+	if t.Amount, ok = args["value"].(*big.Int); !ok {
+		return nil, false
+	}
+	if from, ok := args["from"].(common.Address); ok {
+		t.From = &from
+	}
+	return t, true
+}
@@ -0,0 +1,52 @@
+package monitor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestDecodeTokenTransfer(t *testing.T) {
+	to := common.HexToAddress("0x003be5Df5FeF651EF0C59cD175c73ca1415f53eA")
+	from := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+	// transfer(0x003b..., 1000)
+	transfer := hexutil.MustDecode("0xa9059cbb" +
+		"000000000000000000000000003be5df5fef651ef0c59cd175c73ca1415f53ea" +
+		"00000000000000000000000000000000000000000000000000000000000003e8")
+
+	got, ok := DecodeTokenTransfer(transfer)
+	if !ok {
+		t.Fatal("transfer not decoded")
+	}
+	if got.Method != "transfer" || got.From != nil || got.To != to || got.Amount.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("transfer: got %+v", got)
+	}
+
+	// transferFrom(0xaa, 0x003b..., 1)
+	transferFrom := hexutil.MustDecode("0x23b872dd" +
+		"00000000000000000000000000000000000000000000000000000000000000aa" +
+		"000000000000000000000000003be5df5fef651ef0c59cd175c73ca1415f53ea" +
+		"0000000000000000000000000000000000000000000000000000000000000001")
+
+	got, ok = DecodeTokenTransfer(transferFrom)
+	if !ok {
+		t.Fatal("transferFrom not decoded")
+	}
+	if got.Method != "transferFrom" || got.From == nil || *got.From != from || got.To != to || got.Amount.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("transferFrom: got %+v", got)
+	}
+
+	for _, data := range [][]byte{
+		nil,
+		{0xa9, 0x05},
+		hexutil.MustDecode("0x095ea7b3"), // approve
+		transfer[:SelectorLength+32],     // truncated arguments
+	} {
+		if got, ok := DecodeTokenTransfer(data); ok {
+			t.Errorf("0x%x: unexpected decode %+v", data, got)
+		}
+	}
+}
@@ -0,0 +1,414 @@
+package monitor
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// FilterExpr is a compiled filter expression such as
+//
+//	from == 0xabc... && value > 1e18 && selector == 0xa9059cbb
+//
+// Comparisons of the tx fields from, to, value, gas, gasPrice, nonce and
+// selector with constants combine with &&, ||, ! and parentheses. Amounts
+// are in wei, decimal with an optional exponent or hex. from and to compare
+// with addresses and to also with nil, which a contract creation has;
+// selector compares with 4-byte hex and is nil for txs without one.
+type FilterExpr struct {
+	eval func(*exprEnv) bool
+}
+
+// exprEnv is the tx an expression is evaluated against.
+type exprEnv struct {
+	tx      *types.Transaction
+	chainID *big.Int
+}
+
+// exprKind is the type of a field or constant.
+type exprKind int
+
+const (
+	kindNumber exprKind = iota
+	kindAddress
+	kindSelector
+	kindNil
+	// kindHex is a hex constant, a number, address or selector depending on
+	// what it is compared with
+	kindHex
+)
+
+var kindNames = map[exprKind]string{
+	kindNumber:   "number",
+	kindAddress:  "address",
+	kindSelector: "selector",
+	kindNil:      "nil",
+	kindHex:      "hex",
+}
+
+// exprValue is the value of an operand, a nil ptr stands for nil.
+type exprValue struct {
+	num  *big.Int
+	addr *common.Address
+	sel  []byte
+}
+
+// exprFields are the tx fields an expression can use.
+var exprFields = map[string]struct {
+	kind exprKind
+	get  func(*exprEnv) exprValue
+}{
+	"from": {kindAddress, func(env *exprEnv) exprValue {
+		from, err := Sender(env.chainID, env.tx)
+		if err != nil {
+			return exprValue{}
+		}
+		return exprValue{addr: &from}
+	}},
+	"to":       {kindAddress, func(env *exprEnv) exprValue { return exprValue{addr: env.tx.To()} }},
+	"value":    {kindNumber, func(env *exprEnv) exprValue { return exprValue{num: env.tx.Value()} }},
+	"gas":      {kindNumber, func(env *exprEnv) exprValue { return exprValue{num: new(big.Int).SetUint64(env.tx.Gas())} }},
+	"gasPrice": {kindNumber, func(env *exprEnv) exprValue { return exprValue{num: env.tx.GasPrice()} }},
+	"nonce":    {kindNumber, func(env *exprEnv) exprValue { return exprValue{num: new(big.Int).SetUint64(env.tx.Nonce())} }},
+	"selector": {kindSelector, func(env *exprEnv) exprValue {
+		if len(env.tx.Data()) < SelectorLength {
+			return exprValue{}
+		}
+		return exprValue{sel: env.tx.Data()[:SelectorLength]}
+	}},
+}
+
+// ParseFilterExpr compiles src, reporting syntax and type errors.
+func ParseFilterExpr(src string) (*FilterExpr, error) {
+	toks, err := lexExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{toks: toks}
+	eval, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "unexpected %q", t.text)
+	}
+	return &FilterExpr{eval: eval}, nil
+}
+
+// Filter returns the expression as a Filter, recovering senders with
+// chainID.
+func (e *FilterExpr) Filter(chainID *big.Int) Filter {
+	return func(tx *types.Transaction) bool {
+		return e.eval(&exprEnv{tx: tx, chainID: chainID})
+	}
+}
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokIdent
+	tokNumber
+	tokOp
+)
+
+type exprTok struct {
+	kind tokKind
+	text string
+	pos  int
+}
+
+// exprOps are the operators, the two byte ones first.
+var exprOps = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")"}
+
+func lexExpr(src string) ([]exprTok, error) {
+	var toks []exprTok
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case isIdentByte(c) && !isDigit(c):
+			j := i
+			for j < len(src) && isIdentByte(src[j]) {
+				j++
+			}
+			toks = append(toks, exprTok{tokIdent, src[i:j], i})
+			i = j
+		case isDigit(c):
+			j := i
+			for j < len(src) && (isIdentByte(src[j]) || src[j] == '.' ||
+				((src[j] == '+' || src[j] == '-') && (src[j-1] == 'e' || src[j-1] == 'E') && !strings.HasPrefix(src[i:], "0x"))) {
+				j++
+			}
+			toks = append(toks, exprTok{tokNumber, src[i:j], i})
+			i = j
+		default:
+			op := ""
+			for _, o := range exprOps {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("filter: unexpected %q at %d", c, i+1)
+			}
+			toks = append(toks, exprTok{tokOp, op, i})
+			i += len(op)
+		}
+	}
+	return append(toks, exprTok{tokEOF, "end", len(src)}), nil
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isIdentByte(c byte) bool {
+	return isDigit(c) || c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+type exprParser struct {
+	toks []exprTok
+	pos  int
+}
+
+func (p *exprParser) peek() exprTok { return p.toks[p.pos] }
+
+func (p *exprParser) next() exprTok {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *exprParser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokOp && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) errorf(t exprTok, format string, args ...interface{}) error {
+	return fmt.Errorf("filter: %s at %d", fmt.Sprintf(format, args...), t.pos+1)
+}
+
+// or := and { "||" and }
+func (p *exprParser) or() (func(*exprEnv) bool, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(env *exprEnv) bool { return l(env) || right(env) }
+	}
+	return left, nil
+}
+
+// and := unary { "&&" unary }
+func (p *exprParser) and() (func(*exprEnv) bool, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(env *exprEnv) bool { return l(env) && right(env) }
+	}
+	return left, nil
+}
+
+// unary := "!" unary | "(" or ")" | comparison
+func (p *exprParser) unary() (func(*exprEnv) bool, error) {
+	if p.accept("!") {
+		inner, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(env *exprEnv) bool { return !inner(env) }, nil
+	}
+	if p.accept("(") {
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			t := p.peek()
+			return nil, p.errorf(t, "expected ) instead of %q", t.text)
+		}
+		return inner, nil
+	}
+	return p.comparison()
+}
+
+// exprOperand is a field or a constant of a comparison.
+type exprOperand struct {
+	tok   exprTok
+	kind  exprKind
+	field func(*exprEnv) exprValue
+	value exprValue
+}
+
+func (p *exprParser) operand() (*exprOperand, error) {
+	t := p.next()
+	switch t.kind {
+	case tokIdent:
+		if t.text == "nil" {
+			return &exprOperand{tok: t, kind: kindNil}, nil
+		}
+		f, ok := exprFields[t.text]
+		if !ok {
+			return nil, p.errorf(t, "unknown field %q", t.text)
+		}
+		return &exprOperand{tok: t, kind: f.kind, field: f.get}, nil
+	case tokNumber:
+		if strings.HasPrefix(t.text, "0x") || strings.HasPrefix(t.text, "0X") {
+			if len(t.text) == 2 || strings.Trim(t.text[2:], "0123456789abcdefABCDEF") != "" {
+				return nil, p.errorf(t, "bad hex %q", t.text)
+			}
+			return &exprOperand{tok: t, kind: kindHex}, nil
+		}
+		r, ok := new(big.Rat).SetString(t.text)
+		if !ok || !r.IsInt() {
+			return nil, p.errorf(t, "bad number %q", t.text)
+		}
+		return &exprOperand{tok: t, kind: kindNumber, value: exprValue{num: r.Num()}}, nil
+	}
+	return nil, p.errorf(t, "expected a field or constant instead of %q", t.text)
+}
+
+// comparison := operand ( "==" | "!=" | "<" | "<=" | ">" | ">=" ) operand
+func (p *exprParser) comparison() (func(*exprEnv) bool, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	opTok := p.next()
+	op := opTok.text
+	switch {
+	case opTok.kind != tokOp:
+		return nil, p.errorf(opTok, "expected a comparison instead of %q", op)
+	case op == "==", op == "!=", op == "<", op == "<=", op == ">", op == ">=":
+	default:
+		return nil, p.errorf(opTok, "expected a comparison instead of %q", op)
+	}
+	right, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+
+	if left.field == nil && right.field == nil {
+		return nil, p.errorf(left.tok, "%s %s %s compares two constants", left.tok.text, op, right.tok.text)
+	}
+	kind := left.kind
+	if kind == kindHex || kind == kindNil {
+		kind = right.kind
+	}
+	for _, o := range []*exprOperand{left, right} {
+		if err := o.resolve(p, kind); err != nil {
+			return nil, err
+		}
+	}
+	if kind != kindNumber && op != "==" && op != "!=" {
+		return nil, p.errorf(opTok, "%s %s orders a %s", left.tok.text, op, kindNames[kind])
+	}
+
+	lget, rget := left.get(), right.get()
+	return func(env *exprEnv) bool {
+		cmp, ok := compareExpr(kind, lget(env), rget(env))
+		switch op {
+		case "==":
+			return ok && cmp == 0
+		case "!=":
+			return !ok || cmp != 0
+		case "<":
+			return ok && cmp < 0
+		case "<=":
+			return ok && cmp <= 0
+		case ">":
+			return ok && cmp > 0
+		default:
+			return ok && cmp >= 0
+		}
+	}, nil
+}
+
+// resolve checks that o can be compared as kind, converting a hex constant.
+func (o *exprOperand) resolve(p *exprParser, kind exprKind) error {
+	switch {
+	case o.kind == kindHex:
+		// odd lengths are fine for numbers only
+		b, _ := hexutil.Decode(o.tok.text)
+		switch kind {
+		case kindAddress:
+			if len(b) != common.AddressLength {
+				return p.errorf(o.tok, "%q is no address", o.tok.text)
+			}
+			addr := common.BytesToAddress(b)
+			o.value.addr = &addr
+		case kindSelector:
+			if len(b) != SelectorLength {
+				return p.errorf(o.tok, "%q is no 4-byte selector", o.tok.text)
+			}
+			o.value.sel = b
+		default:
+			n, ok := new(big.Int).SetString(o.tok.text[2:], 16)
+			if !ok {
+				return p.errorf(o.tok, "bad number %q", o.tok.text)
+			}
+			o.value.num = n
+		}
+	case o.kind == kindNil:
+		if kind == kindNumber {
+			return p.errorf(o.tok, "a number is never nil")
+		}
+	case o.kind != kind:
+		return p.errorf(o.tok, "%s is a %s, not a %s", o.tok.text, kindNames[o.kind], kindNames[kind])
+	}
+	return nil
+}
+
+func (o *exprOperand) get() func(*exprEnv) exprValue {
+	if o.field != nil {
+		return o.field
+	}
+	v := o.value
+	return func(*exprEnv) exprValue { return v }
+}
+
+// compareExpr compares a with b, ok is false if either of them is nil
+// while the other isn't. Two nils are equal.
+func compareExpr(kind exprKind, a, b exprValue) (cmp int, ok bool) {
+	switch kind {
+	case kindNumber:
+		if a.num == nil || b.num == nil {
+			return 0, false
+		}
+		return a.num.Cmp(b.num), true
+	case kindAddress:
+		if a.addr == nil || b.addr == nil {
+			return 0, a.addr == nil && b.addr == nil
+		}
+		return bytes.Compare(a.addr[:], b.addr[:]), true
+	default:
+		if a.sel == nil || b.sel == nil {
+			return 0, a.sel == nil && b.sel == nil
+		}
+		return bytes.Compare(a.sel, b.sel), true
+	}
+}
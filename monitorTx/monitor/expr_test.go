@@ -0,0 +1,86 @@
+package monitor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestFilterExpr(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	chainID := big.NewInt(1)
+	signer := types.LatestSignerForChainID(chainID)
+
+	to := common.HexToAddress("0x003be5Df5FeF651EF0C59cD175c73ca1415f53eA")
+	transfer, _ := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: 3, To: &to, Gas: 60000,
+		GasPrice: big.NewInt(7), Value: big.NewInt(2 * params.Ether), Data: []byte{0xa9, 0x05, 0x9c, 0xbb, 1}})
+	creation, _ := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: 4, Gas: 53000, GasPrice: big.NewInt(7)})
+
+	tests := []struct {
+		expr               string
+		transfer, creation bool
+	}{
+		{"from == " + from, true, true},
+		{"from != " + from, false, false},
+		{"to == " + to.Hex(), true, false},
+		{"to == nil", false, true},
+		{"nil != to", true, false},
+		{"value > 1e18", true, false},
+		{"value >= 2e18 && value <= 2000000000000000000", true, false},
+		{"1.5e18 < value", true, false},
+		{"gas == 0xea60", true, false},
+		{"gasPrice < 10 && nonce == 3", true, false},
+		{"selector == 0xa9059cbb", true, false},
+		{"selector == nil", false, true},
+		{"selector != 0x095ea7b3", true, true},
+		{"nonce == 3 || nonce == 4", true, true},
+		{"!(nonce == 3) && from == " + from, false, true},
+		{"from == " + from + " && (value > 1e18 || to == nil)", true, true},
+		{"from == to", false, false},
+	}
+
+	for _, tt := range tests {
+		e, err := ParseFilterExpr(tt.expr)
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		f := e.Filter(chainID)
+		if got := f(transfer); got != tt.transfer {
+			t.Errorf("%s: transfer got %v", tt.expr, got)
+		}
+		if got := f(creation); got != tt.creation {
+			t.Errorf("%s: creation got %v", tt.expr, got)
+		}
+	}
+}
+
+func TestFilterExprErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"value",
+		"value >",
+		"value > 1e18 &&",
+		"(value > 1",
+		"balance > 1",
+		"value > 0.5",
+		"value > 0xzz",
+		"from == 0x1234",
+		"from > 0x003be5Df5FeF651EF0C59cD175c73ca1415f53eA",
+		"selector == 0x1234",
+		"value == nil",
+		"value == from",
+		"1 == 1",
+		"value > 1 nonce",
+		"value ~ 1",
+	} {
+		if _, err := ParseFilterExpr(expr); err == nil {
+			t.Errorf("%q parsed", expr)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package monitor
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Filter reports whether a matched tx should be handed to the handler.
+type Filter func(tx *types.Transaction) bool
+
+// AndFilter passes txs passing all filters, it passes everything if empty.
+func AndFilter(filters ...Filter) Filter {
+	return func(tx *types.Transaction) bool {
+		for _, f := range filters {
+			if !f(tx) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// OrFilter passes txs passing any of filters, it passes nothing if empty.
+func OrFilter(filters ...Filter) Filter {
+	return func(tx *types.Transaction) bool {
+		for _, f := range filters {
+			if f(tx) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MinValueFilter passes txs carrying at least min wei.
+func MinValueFilter(min *big.Int) Filter {
+	return ValueRangeFilter(min, nil)
+}
+
+// ValueRangeFilter passes txs carrying between min and max wei inclusive,
+// a nil bound is unbounded.
+func ValueRangeFilter(min, max *big.Int) Filter {
+	return func(tx *types.Transaction) bool {
+		if min != nil && tx.Value().Cmp(min) < 0 {
+			return false
+		}
+		return max == nil || tx.Value().Cmp(max) <= 0
+	}
+}
+
+// GasPriceFilter passes txs offering at least min wei per gas to the miner:
+// the gas price of legacy and access list txs, and the tip of fee market
+// txs, which never exceeds their fee cap.
+func GasPriceFilter(min *big.Int) Filter {
+	return func(tx *types.Transaction) bool {
+		price := tx.GasPrice()
+		if hasFeeCap(tx) {
+			price = tx.GasTipCap()
+			if tx.GasFeeCap().Cmp(price) < 0 {
+				price = tx.GasFeeCap()
+			}
+		}
+		return price.Cmp(min) >= 0
+	}
+}
+
+// NonceFilter passes txs with a nonce between min and max inclusive, a nil
+// bound is unbounded.
+func NonceFilter(min, max *uint64) Filter {
+	return func(tx *types.Transaction) bool {
+		if min != nil && tx.Nonce() < *min {
+			return false
+		}
+		return max == nil || tx.Nonce() <= *max
+	}
+}
+
+// ToFilter passes txs whose recipient is in allow, or anywhere if allow is
+// empty, unless it is in deny. Contract creations, without a recipient, only
+// pass if creations is set.
+func ToFilter(allow, deny map[common.Address]struct{}, creations bool) Filter {
+	return func(tx *types.Transaction) bool {
+		to := tx.To()
+		if to == nil {
+			return creations
+		}
+		if _, ok := deny[*to]; ok {
+			return false
+		}
+		if len(allow) == 0 {
+			return true
+		}
+		_, ok := allow[*to]
+		return ok
+	}
+}
+
+// CreationFilter passes contract creations only.
+func CreationFilter() Filter {
+	return func(tx *types.Transaction) bool {
+		return tx.To() == nil
+	}
+}
+
+// DataFilter passes the txs carrying input data, such as contract calls,
+// if withData is set, or else the ones without, such as plain transfers.
+func DataFilter(withData bool) Filter {
+	return func(tx *types.Transaction) bool {
+		return (len(tx.Data()) > 0) == withData
+	}
+}
+
+// SelectorFilter passes txs calling one of selectors. Txs with less than
+// SelectorLength bytes of data, such as plain transfers, never pass.
+func SelectorFilter(selectors map[[SelectorLength]byte]struct{}) Filter {
+	return func(tx *types.Transaction) bool {
+		if len(tx.Data()) < SelectorLength {
+			return false
+		}
+
+		var sel [SelectorLength]byte
+		copy(sel[:], tx.Data())
+		_, ok := selectors[sel]
+		return ok
+	}
+}
+
+// AccessListFilter passes txs whose access list includes one of addrs.
+// Legacy txs have no access list and never pass.
+func AccessListFilter(addrs map[common.Address]struct{}) Filter {
+	return func(tx *types.Transaction) bool {
+		for _, tuple := range tx.AccessList() {
+			if _, ok := addrs[tuple.Address]; ok {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,251 @@
+package monitor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func valueTx(wei int64, data []byte) *types.Transaction {
+	return types.NewTransaction(0, common.Address{}, big.NewInt(wei), 21000, big.NewInt(1), data)
+}
+
+func TestCombinators(t *testing.T) {
+	yes := func(*types.Transaction) bool { return true }
+	no := func(*types.Transaction) bool { return false }
+	tx := valueTx(0, nil)
+
+	tests := []struct {
+		name string
+		f    Filter
+		want bool
+	}{
+		{"and empty", AndFilter(), true},
+		{"and all", AndFilter(yes, yes), true},
+		{"and one", AndFilter(yes, no), false},
+		{"or empty", OrFilter(), false},
+		{"or one", OrFilter(no, yes), true},
+		{"or none", OrFilter(no, no), false},
+		{"nested", AndFilter(yes, OrFilter(no, yes)), true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.f(tx); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMinValueFilter(t *testing.T) {
+	f := MinValueFilter(big.NewInt(100))
+
+	for wei, want := range map[int64]bool{0: false, 99: false, 100: true, 101: true} {
+		if got := f(valueTx(wei, nil)); got != want {
+			t.Errorf("%d wei: got %v, want %v", wei, got, want)
+		}
+	}
+}
+
+func TestValueRangeFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max *big.Int
+		wei      int64
+		want     bool
+	}{
+		{"below", big.NewInt(100), big.NewInt(200), 99, false},
+		{"at min", big.NewInt(100), big.NewInt(200), 100, true},
+		{"inside", big.NewInt(100), big.NewInt(200), 150, true},
+		{"at max", big.NewInt(100), big.NewInt(200), 200, true},
+		{"above", big.NewInt(100), big.NewInt(200), 201, false},
+		{"single value", big.NewInt(100), big.NewInt(100), 100, true},
+		{"no max", big.NewInt(100), nil, 1 << 62, true},
+		{"no min", nil, big.NewInt(200), 0, true},
+		{"unbounded", nil, nil, 0, true},
+	}
+
+	for _, tt := range tests {
+		if got := ValueRangeFilter(tt.min, tt.max)(valueTx(tt.wei, nil)); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGasPriceFilter(t *testing.T) {
+	to := common.Address{1}
+	legacy := func(price int64) *types.Transaction {
+		return types.NewTx(&types.LegacyTx{To: &to, Gas: 21000, GasPrice: big.NewInt(gwei(price))})
+	}
+	accessList := func(price int64) *types.Transaction {
+		return types.NewTx(&types.AccessListTx{ChainID: big.NewInt(1), To: &to, Gas: 21000, GasPrice: big.NewInt(gwei(price))})
+	}
+	dynamic := func(tip, feeCap int64) *types.Transaction {
+		return types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), To: &to, Gas: 21000,
+			GasTipCap: big.NewInt(gwei(tip)), GasFeeCap: big.NewInt(gwei(feeCap))})
+	}
+
+	tests := []struct {
+		name string
+		tx   *types.Transaction
+		want bool
+	}{
+		{"legacy below", legacy(9), false},
+		{"legacy at min", legacy(10), true},
+		{"access list above", accessList(11), true},
+		{"access list below", accessList(9), false},
+		// a high fee cap with a low tip is no priority tx
+		{"dynamic low tip", dynamic(2, 100), false},
+		{"dynamic at min", dynamic(10, 100), true},
+		{"dynamic tip above fee cap", dynamic(50, 8), false},
+		{"dynamic tip capped at min", dynamic(50, 10), true},
+	}
+
+	filter := GasPriceFilter(big.NewInt(gwei(10)))
+	for _, tt := range tests {
+		if got := filter(tt.tx); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNonceFilter(t *testing.T) {
+	n := func(v uint64) *uint64 { return &v }
+
+	tests := []struct {
+		name     string
+		min, max *uint64
+		nonce    uint64
+		want     bool
+	}{
+		{"any", nil, nil, 7, true},
+		{"exact", n(42), n(42), 42, true},
+		{"exact below", n(42), n(42), 41, false},
+		{"exact above", n(42), n(42), 43, false},
+		{"at min", n(5), nil, 5, true},
+		{"below min", n(5), nil, 4, false},
+		{"at max", nil, n(5), 5, true},
+		{"above max", nil, n(5), 6, false},
+		{"zero max", nil, n(0), 0, true},
+	}
+
+	for _, tt := range tests {
+		tx := types.NewTransaction(tt.nonce, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil)
+		if got := NonceFilter(tt.min, tt.max)(tx); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestToFilter(t *testing.T) {
+	a := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	b := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	c := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	set := func(addrs ...common.Address) map[common.Address]struct{} { return AddressSet(addrs) }
+
+	tests := []struct {
+		name        string
+		allow, deny map[common.Address]struct{}
+		creations   bool
+		to          *common.Address
+		want        bool
+	}{
+		{"no lists", nil, nil, false, &a, true},
+		{"allowed", set(a), nil, false, &a, true},
+		{"not allowed", set(a), nil, false, &b, false},
+		{"denied", nil, set(a), false, &a, false},
+		{"not denied", nil, set(a), false, &b, true},
+		{"deny wins", set(a, b), set(a), false, &a, false},
+		{"allowed not denied", set(a, b), set(a), false, &b, true},
+		{"neither", set(a), set(b), false, &c, false},
+		{"creation kept", set(a), set(b), true, nil, true},
+		{"creation dropped", nil, nil, false, nil, false},
+	}
+
+	for _, tt := range tests {
+		var tx *types.Transaction
+		if tt.to == nil {
+			tx = types.NewContractCreation(0, big.NewInt(0), 53000, big.NewInt(1), nil)
+		} else {
+			tx = types.NewTransaction(0, *tt.to, big.NewInt(0), 21000, big.NewInt(1), nil)
+		}
+		if got := ToFilter(tt.allow, tt.deny, tt.creations)(tx); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCreationFilter(t *testing.T) {
+	f := CreationFilter()
+	if !f(types.NewContractCreation(0, big.NewInt(0), 53000, big.NewInt(1), nil)) {
+		t.Error("contract creation filtered out")
+	}
+	if f(valueTx(0, nil)) {
+		t.Error("call passed")
+	}
+}
+
+func TestDataFilter(t *testing.T) {
+	call, transfer := valueTx(0, []byte{0xa9}), valueTx(1, nil)
+	if f := DataFilter(true); !f(call) || f(transfer) {
+		t.Error("DataFilter(true) should pass calls only")
+	}
+	if f := DataFilter(false); f(call) || !f(transfer) {
+		t.Error("DataFilter(false) should pass transfers only")
+	}
+}
+
+func TestSelectorFilter(t *testing.T) {
+	f := SelectorFilter(map[[SelectorLength]byte]struct{}{
+		{0xa9, 0x05, 0x9c, 0xbb}: {},
+	})
+
+	tests := []struct {
+		data []byte
+		want bool
+	}{
+		{nil, false},
+		{[]byte{0xa9, 0x05, 0x9c}, false},
+		{[]byte{0xa9, 0x05, 0x9c, 0xbb}, true},
+		{[]byte{0xa9, 0x05, 0x9c, 0xbb, 0x01}, true},
+		{[]byte{0x09, 0x5e, 0xa7, 0xb3}, false},
+	}
+
+	for _, tt := range tests {
+		if got := f(valueTx(0, tt.data)); got != tt.want {
+			t.Errorf("0x%x: got %v, want %v", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestAccessListFilter(t *testing.T) {
+	pool, other := common.Address{0xaa}, common.Address{0xbb}
+	accessList := func(addrs ...common.Address) *types.Transaction {
+		var list types.AccessList
+		for _, a := range addrs {
+			list = append(list, types.AccessTuple{Address: a, StorageKeys: []common.Hash{{1}}})
+		}
+		return types.NewTx(&types.AccessListTx{ChainID: big.NewInt(1), To: &other, Gas: 21000, GasPrice: big.NewInt(1), AccessList: list})
+	}
+
+	tests := []struct {
+		name string
+		tx   *types.Transaction
+		want bool
+	}{
+		{"listed", accessList(other, pool), true},
+		{"not listed", accessList(other), false},
+		{"empty list", accessList(), false},
+		{"legacy", valueTx(0, nil), false},
+		{"dynamic", types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), To: &other, Gas: 21000,
+			GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(1), AccessList: types.AccessList{{Address: pool}}}), true},
+	}
+
+	filter := AccessListFilter(AddressSet([]common.Address{pool}))
+	for _, tt := range tests {
+		if got := filter(tt.tx); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
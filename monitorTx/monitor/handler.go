@@ -0,0 +1,88 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// HandlerLog is the built-in handler doing nothing beyond the log line of
+// every match.
+const HandlerLog = "log"
+
+// Match is a matched tx handed to a Handler.
+type Match struct {
+	Tx     *types.Transaction
+	Record *TxRecord
+
+	// Client is the connection of the Monitor that matched Tx.
+	Client TxClient
+
+	// Receipt is set once Tx is mined, for the handlers run by Confirmed.
+	Receipt *types.Receipt
+}
+
+// Handler acts on matched txs. It is registered by name with
+// RegisterHandler and picked with the -action flag of the command.
+type Handler interface {
+	Handle(ctx context.Context, m Match) error
+}
+
+// HandlerFunc adapts a func to a Handler.
+type HandlerFunc func(ctx context.Context, m Match) error
+
+func (f HandlerFunc) Handle(ctx context.Context, m Match) error {
+	return f(ctx, m)
+}
+
+var handlers = struct {
+	sync.Mutex
+	byName map[string]Handler
+}{byName: map[string]Handler{
+	HandlerLog: HandlerFunc(func(context.Context, Match) error { return nil }),
+}}
+
+// RegisterHandler makes h available as name, replacing an earlier handler
+// of that name. Handlers needing settings, such as the Webhook, Telegram
+// and Responder built into the command, are registered once configured.
+func RegisterHandler(name string, h Handler) {
+	handlers.Lock()
+	defer handlers.Unlock()
+	handlers.byName[name] = h
+}
+
+// LookupHandler returns the handler registered as name.
+func LookupHandler(name string) (Handler, bool) {
+	handlers.Lock()
+	defer handlers.Unlock()
+	h, ok := handlers.byName[name]
+	return h, ok
+}
+
+// HandlerNames returns the names of the registered handlers, sorted.
+func HandlerNames() []string {
+	handlers.Lock()
+	defer handlers.Unlock()
+	names := make([]string, 0, len(handlers.byName))
+	for name := range handlers.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveHandlers looks up the handlers of names, in order.
+func ResolveHandlers(names []string) ([]Handler, error) {
+	out := make([]Handler, 0, len(names))
+	for _, name := range names {
+		h, ok := LookupHandler(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown action %q", name)
+		}
+		out = append(out, h)
+	}
+	return out, nil
+}
@@ -0,0 +1,47 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestResolveHandlers(t *testing.T) {
+	RegisterHandler("test-noop", HandlerFunc(func(context.Context, Match) error { return nil }))
+
+	got, err := ResolveHandlers([]string{HandlerLog, "test-noop"})
+	if err != nil || len(got) != 2 {
+		t.Fatalf("resolved %d handlers, err %v", len(got), err)
+	}
+	if _, err := ResolveHandlers([]string{"test-missing"}); err == nil {
+		t.Error("resolved an unregistered handler")
+	}
+}
+
+func ExampleRegisterHandler() {
+	// count the matches moving more than 1 ETH, picked with -action whales
+	oneEther := big.NewInt(params.Ether)
+	var whales int
+	RegisterHandler("whales", HandlerFunc(func(ctx context.Context, m Match) error {
+		if m.Record.Value.Cmp(oneEther) > 0 {
+			whales++
+		}
+		return nil
+	}))
+
+	handlers, err := ResolveHandlers([]string{HandlerLog, "whales"})
+	if err != nil {
+		panic(err)
+	}
+	tx := types.NewTransaction(0, common.Address{1}, big.NewInt(2*params.Ether), 21000, big.NewInt(1), nil)
+	for _, h := range handlers {
+		h.Handle(context.Background(), Match{Tx: tx, Record: NewTxRecord(tx, common.Address{2})})
+	}
+	fmt.Println(whales)
+	// Output: 1
+}
@@ -0,0 +1,113 @@
+package monitor
+
+import (
+	"context"
+	"log/slog"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// headMaxAge is how often the head is fetched while WatchHeads is off, a
+// fraction of the block time so that it follows every block.
+const headMaxAge = 4 * time.Second
+
+// headCache holds the latest block header known to a Monitor.
+type headCache struct {
+	mu   sync.Mutex
+	head *types.Header
+}
+
+// Head returns the latest block header, nil if none is known yet. It never
+// blocks on the node: the newHeads subscription of WatchHeads or, without
+// it, watchHead keeps the cached header current.
+func (m *Monitor) Head() *types.Header {
+	return m.head.latest()
+}
+
+// refreshHead fetches the latest block header into the cache. On failure
+// the cached one is kept.
+func (m *Monitor) refreshHead(ctx context.Context) {
+	client := m.Client()
+	if client == nil {
+		return
+	}
+	if m.FetchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.FetchTimeout)
+		defer cancel()
+	}
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Warn("Fetch latest header", "endpoint", m.URL, "err", err)
+		}
+		return
+	}
+	m.head.set(head)
+}
+
+// watchHead refreshes the cached header every headMaxAge. It returns once
+// ctx is done.
+func (m *Monitor) watchHead(ctx context.Context) {
+	ticker := time.NewTicker(headMaxAge)
+	defer ticker.Stop()
+
+	for {
+		m.refreshHead(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// set caches h, the header of a new block.
+func (c *headCache) set(h *types.Header) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.head = h
+}
+
+// latest returns the cached header without fetching it, nil if there is
+// none yet.
+func (c *headCache) latest() *types.Header {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.head
+}
+
+// BaseFee returns the base fee of the latest block, nil if unknown or the
+// chain has none.
+func (m *Monitor) BaseFee() *big.Int {
+	if head := m.Head(); head != nil {
+		return head.BaseFee
+	}
+	return nil
+}
+
+// hasFeeCap reports whether tx pays a base fee plus tip, capped by its fee
+// cap, rather than a plain gas price.
+func hasFeeCap(tx *types.Transaction) bool {
+	return tx.Type() != types.LegacyTxType && tx.Type() != types.AccessListTxType
+}
+
+// EffectiveGasPrice returns the price per gas tx pays in a block with
+// baseFee: the smaller of its fee cap and baseFee plus its tip. It is the
+// gas price of txs without a fee cap, and nil if baseFee is unknown.
+func EffectiveGasPrice(tx *types.Transaction, baseFee *big.Int) *big.Int {
+	if !hasFeeCap(tx) {
+		return tx.GasPrice()
+	}
+	if baseFee == nil {
+		return nil
+	}
+	price := new(big.Int).Add(baseFee, tx.GasTipCap())
+	if price.Cmp(tx.GasFeeCap()) > 0 {
+		price.Set(tx.GasFeeCap())
+	}
+	return price
+}
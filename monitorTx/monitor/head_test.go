@@ -0,0 +1,63 @@
+package monitor
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestEffectiveGasPrice(t *testing.T) {
+	to := common.Address{1}
+	dynamic := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), To: &to, Gas: 21000,
+		GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(30)})
+
+	tests := []struct {
+		name    string
+		tx      *types.Transaction
+		baseFee *big.Int
+		want    *big.Int
+	}{
+		{"base fee plus tip", dynamic, big.NewInt(20), big.NewInt(22)},
+		{"capped", dynamic, big.NewInt(29), big.NewInt(30)},
+		{"unknown base fee", dynamic, nil, nil},
+		{"legacy", valueTx(0, nil), big.NewInt(20), big.NewInt(1)},
+	}
+	for _, tt := range tests {
+		got := EffectiveGasPrice(tt.tx, tt.baseFee)
+		if (got == nil) != (tt.want == nil) || (got != nil && got.Cmp(tt.want) != 0) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if rec := NewTxRecord(dynamic, common.Address{}); rec.Tip == nil || rec.Tip.Int64() != 2 {
+		t.Errorf("record tip %v, want 2", rec.Tip)
+	}
+	if rec := NewTxRecord(valueTx(0, nil), common.Address{}); rec.Tip != nil {
+		t.Errorf("legacy record has tip %v", rec.Tip)
+	}
+}
+
+func TestHeadCached(t *testing.T) {
+	client := newFakeClient()
+	m := &Monitor{client: client}
+
+	if fee := m.BaseFee(); fee != nil {
+		t.Fatalf("base fee %v before any fetch", fee)
+	}
+	m.refreshHead(context.Background())
+	if fee := m.BaseFee(); fee == nil || fee.Cmp(big.NewInt(gwei(20))) != 0 {
+		t.Fatalf("base fee %v, want 20 gwei", fee)
+	}
+
+	client.baseFee = big.NewInt(gwei(30))
+	if fee := m.BaseFee(); fee.Cmp(big.NewInt(gwei(20))) != 0 {
+		t.Errorf("base fee %v fetched by Head", fee)
+	}
+	m.refreshHead(context.Background())
+	if fee := m.BaseFee(); fee.Cmp(big.NewInt(gwei(30))) != 0 {
+		t.Errorf("refreshed base fee %v, want 30 gwei", fee)
+	}
+}
@@ -0,0 +1,36 @@
+package monitor
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// DefaultHeartbeat is the quiet period after which Run logs a heartbeat.
+const DefaultHeartbeat = 30 * time.Second
+
+// heartbeat logs every Heartbeat in which no pending tx arrived so that a
+// stalled subscription shows in the log. It returns once ctx is done.
+func (m *Monitor) heartbeat(ctx context.Context) {
+	ticker := time.NewTicker(m.Heartbeat)
+	defer ticker.Stop()
+
+	last := m.received.Load()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		total := m.received.Load()
+		if total == last {
+			attrs := []any{"interval", m.Heartbeat, "seen", total}
+			if head := m.head.latest(); head != nil {
+				attrs = append(attrs, "block", head.Number)
+			}
+			slog.Info("Heartbeat: no pending txs", attrs...)
+		}
+		last = total
+	}
+}
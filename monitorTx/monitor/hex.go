@@ -0,0 +1,218 @@
+package monitor
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	HashLength    = 32
+	AddressLength = 20
+)
+
+// BytesToHash sets b to hash.
+// If b is larger than len(h), b will be cropped from the left.
+func BytesToHash(b []byte) common.Hash {
+	var h common.Hash
+
+	if len(b) > HashLength {
+		b = b[len(b)-HashLength:]
+	}
+
+	copy(h[HashLength-len(b):], b)
+	return h
+}
+
+// BytesToHashStrict is like BytesToHash but rejects b unless it is
+// exactly HashLength bytes long.
+func BytesToHashStrict(b []byte) (common.Hash, error) {
+	if len(b) != HashLength {
+		return common.Hash{}, fmt.Errorf("hash must be %d bytes, got %d", HashLength, len(b))
+	}
+	return BytesToHash(b), nil
+}
+
+// BytesToAddress sets b to address.
+// If b is larger than len(h), b will be cropped from the left.
+func BytesToAddress(b []byte) common.Address {
+	var h common.Address
+
+	if len(b) > AddressLength {
+		b = b[len(b)-AddressLength:]
+	}
+
+	copy(h[AddressLength-len(b):], b)
+	return h
+}
+
+// BytesToAddressStrict is like BytesToAddress but rejects b unless it is
+// exactly AddressLength bytes long.
+func BytesToAddressStrict(b []byte) (common.Address, error) {
+	if len(b) != AddressLength {
+		return common.Address{}, fmt.Errorf("address must be %d bytes, got %d", AddressLength, len(b))
+	}
+	return BytesToAddress(b), nil
+}
+
+func GetHexStringBytes(s string) ([]byte, error) {
+	if len(s) > 1 {
+		if s[0:2] == "0x" || s[0:2] == "0X" {
+			s = s[2:]
+
+			if len(s)%2 != 0 {
+				return []byte{}, fmt.Errorf("hex string %q has odd length", s)
+			}
+
+			hexBytes, err := hex.DecodeString(s)
+			if err != nil {
+				return []byte{}, fmt.Errorf("hex string %q has invalid characters: %v", s, err)
+			}
+			return (hexBytes), nil
+
+		} else {
+			return []byte{}, fmt.Errorf("Not hex string!\n")
+		}
+
+	} else {
+		return []byte{}, fmt.Errorf("Not hex string!\n")
+	}
+}
+
+func HexStringToTxHash(s string) (common.Hash, error) {
+
+	hexBytes, err := GetHexStringBytes(s)
+
+	if err != nil {
+		return [common.HashLength]byte{}, err
+	} else {
+		return BytesToHash(hexBytes), nil
+	}
+}
+
+func HexStringToAddr(s string) (common.Address, error) {
+	hexBytes, err := GetHexStringBytes(s)
+
+	if err != nil {
+		return [common.AddressLength]byte{}, err
+	} else {
+		return BytesToAddress(hexBytes), nil
+	}
+}
+
+// VerifyChecksum checks the EIP-55 checksum of a mixed-case address.
+// All-lowercase and all-uppercase addresses carry no checksum and pass.
+func VerifyChecksum(s string) error {
+	if !common.IsHexAddress(s) {
+		return fmt.Errorf("%q is not a hex address", s)
+	}
+
+	hexPart := s
+	if len(s) > 1 && (s[0:2] == "0x" || s[0:2] == "0X") {
+		hexPart = s[2:]
+	}
+	if hexPart == strings.ToLower(hexPart) || hexPart == strings.ToUpper(hexPart) {
+		return nil
+	}
+
+	if want := common.HexToAddress(s).Hex(); want[2:] != hexPart {
+		return fmt.Errorf("address %s fails its EIP-55 checksum, expected %s", s, want)
+	}
+	return nil
+}
+
+// ParseAddresses converts every entry, 0x and exactly 20 bytes of hex, to
+// an address. All invalid entries are reported together.
+func ParseAddresses(list []string) ([]common.Address, error) {
+	addrs := make([]common.Address, 0, len(list))
+	var invalid []string
+
+	for _, s := range list {
+		b, err := GetHexStringBytes(s)
+		if err != nil {
+			invalid = append(invalid, s)
+			continue
+		}
+		addr, err := BytesToAddressStrict(b)
+		if err != nil {
+			invalid = append(invalid, s)
+			continue
+		}
+		addrs = append(addrs, addr)
+	}
+
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("invalid address: %s", strings.Join(invalid, ", "))
+	}
+	return addrs, nil
+}
+
+// SelectorLength is the size of a method selector at the start of calldata.
+const SelectorLength = 4
+
+// ParseSelectors converts hex strings such as "0xa9059cbb" to a selector set.
+func ParseSelectors(list []string) (map[[SelectorLength]byte]struct{}, error) {
+	set := make(map[[SelectorLength]byte]struct{}, len(list))
+
+	for _, s := range list {
+		b, err := GetHexStringBytes(s)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != SelectorLength {
+			return nil, fmt.Errorf("selector %q must be %d bytes", s, SelectorLength)
+		}
+
+		var sel [SelectorLength]byte
+		copy(sel[:], b)
+		set[sel] = struct{}{}
+	}
+	return set, nil
+}
+
+// AddressSet builds a lookup set from addrs.
+func AddressSet(addrs []common.Address) map[common.Address]struct{} {
+	set := make(map[common.Address]struct{}, len(addrs))
+	for _, a := range addrs {
+		set[a] = struct{}{}
+	}
+	return set
+}
+
+// ParseEther converts a decimal ETH amount such as "0.5" to wei.
+func ParseEther(s string) (*big.Int, error) {
+	return parseUnits(s, params.Ether, "ETH")
+}
+
+// ParseGwei converts a decimal gwei amount such as "1.5" to wei.
+func ParseGwei(s string) (*big.Int, error) {
+	return parseUnits(s, params.GWei, "gwei")
+}
+
+// FormatEther formats wei as a decimal ETH amount without trailing zeros.
+func FormatEther(wei *big.Int) string {
+	if wei == nil {
+		return "0"
+	}
+
+	s := new(big.Rat).SetFrac(wei, big.NewInt(params.Ether)).FloatString(18)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+func parseUnits(s string, unit int64, name string) (*big.Int, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || r.Sign() < 0 {
+		return nil, fmt.Errorf("invalid %s amount %q", name, s)
+	}
+
+	r.Mul(r, new(big.Rat).SetInt64(unit))
+	if !r.IsInt() {
+		return nil, fmt.Errorf("%s amount %q is finer than 1 wei", name, s)
+	}
+	return new(big.Int).Set(r.Num()), nil
+}
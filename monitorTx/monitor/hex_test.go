@@ -0,0 +1,184 @@
+package monitor
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func seq(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i + 1)
+	}
+	return b
+}
+
+func TestBytesToHash(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		want []byte
+	}{
+		{"empty", nil, make([]byte, HashLength)},
+		{"exact", seq(HashLength), seq(HashLength)},
+		{"over", seq(HashLength + 2), seq(HashLength + 2)[2:]},
+		{"under", []byte{0xaa, 0xbb}, append(make([]byte, HashLength-2), 0xaa, 0xbb)},
+	}
+
+	for _, tt := range tests {
+		if got := BytesToHash(tt.in); !bytes.Equal(got[:], tt.want) {
+			t.Errorf("%s: got %x, want %x", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBytesToAddress(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		want []byte
+	}{
+		{"empty", nil, make([]byte, AddressLength)},
+		{"exact", seq(AddressLength), seq(AddressLength)},
+		{"over", seq(AddressLength + 2), seq(AddressLength + 2)[2:]},
+		{"under", []byte{0xaa, 0xbb}, append(make([]byte, AddressLength-2), 0xaa, 0xbb)},
+	}
+
+	for _, tt := range tests {
+		if got := BytesToAddress(tt.in); !bytes.Equal(got[:], tt.want) {
+			t.Errorf("%s: got %x, want %x", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBytesToStrict(t *testing.T) {
+	for _, n := range []int{0, 2, AddressLength + 1, HashLength + 1} {
+		if _, err := BytesToAddressStrict(seq(n)); err == nil {
+			t.Errorf("BytesToAddressStrict accepted %d bytes", n)
+		}
+		if _, err := BytesToHashStrict(seq(n)); err == nil {
+			t.Errorf("BytesToHashStrict accepted %d bytes", n)
+		}
+	}
+
+	addr, err := BytesToAddressStrict(seq(AddressLength))
+	if err != nil || addr != common.BytesToAddress(seq(AddressLength)) {
+		t.Errorf("BytesToAddressStrict: got %x, %v", addr, err)
+	}
+
+	hash, err := BytesToHashStrict(seq(HashLength))
+	if err != nil || hash != common.BytesToHash(seq(HashLength)) {
+		t.Errorf("BytesToHashStrict: got %x, %v", hash, err)
+	}
+}
+
+func TestGetHexStringBytes(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []byte
+		wantErr string
+	}{
+		{"0x", []byte{}, ""},
+		{"0xabcd", []byte{0xab, 0xcd}, ""},
+		{"0XABCD", []byte{0xab, 0xcd}, ""},
+		{"0xabc", nil, "odd length"},
+		{"0xgg", nil, "invalid characters"},
+		{"abcd", nil, "Not hex string"},
+		{"", nil, "Not hex string"},
+	}
+
+	for _, tt := range tests {
+		got, err := GetHexStringBytes(tt.in)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%q: got error %v, want %q", tt.in, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || !bytes.Equal(got, tt.want) {
+			t.Errorf("%q: got %x, %v, want %x", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestEther(t *testing.T) {
+	tests := []struct {
+		eth string
+		wei string
+	}{
+		{"0", "0"},
+		{"1", "1000000000000000000"},
+		{"0.5", "500000000000000000"},
+		{"0.000000000000000001", "1"},
+		{"1234.25", "1234250000000000000000"},
+	}
+
+	for _, tt := range tests {
+		wei, err := ParseEther(tt.eth)
+		if err != nil || wei.String() != tt.wei {
+			t.Errorf("ParseEther(%q): got %v, %v, want %s", tt.eth, wei, err, tt.wei)
+			continue
+		}
+		if got := FormatEther(wei); got != tt.eth {
+			t.Errorf("FormatEther(%s): got %q, want %q", tt.wei, got, tt.eth)
+		}
+	}
+
+	for _, bad := range []string{"", "abc", "-1", "0.0000000000000000001"} {
+		if _, err := ParseEther(bad); err == nil {
+			t.Errorf("ParseEther(%q) accepted", bad)
+		}
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	tests := []struct {
+		in string
+		ok bool
+	}{
+		{"0x003be5Df5FeF651EF0C59cD175c73ca1415f53eA", true},
+		{"0x003be5df5fef651ef0c59cd175c73ca1415f53ea", true},
+		{"0x003BE5DF5FEF651EF0C59CD175C73CA1415F53EA", true},
+		{"0x003Be5Df5FeF651EF0C59cD175c73ca1415f53eA", false},
+		{"0x003be5", false},
+	}
+
+	for _, tt := range tests {
+		if err := VerifyChecksum(tt.in); (err == nil) != tt.ok {
+			t.Errorf("%s: got %v, want ok=%v", tt.in, err, tt.ok)
+		}
+	}
+}
+
+func TestParseAddresses(t *testing.T) {
+	valid := "0x003be5Df5FeF651EF0C59cD175c73ca1415f53eA"
+	tests := []struct {
+		in []string
+		ok bool
+	}{
+		{[]string{valid, "0x003be5df5fef651ef0c59cd175c73ca1415f53ea"}, true},
+		{[]string{"0x1234"}, false},
+		{[]string{valid + "00"}, false},
+		{[]string{"003be5df5fef651ef0c59cd175c73ca1415f53ea"}, false},
+		{[]string{valid, "0x1234"}, false},
+	}
+
+	for _, tt := range tests {
+		addrs, err := ParseAddresses(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("%v: got %v, want ok=%v", tt.in, err, tt.ok)
+			continue
+		}
+		if tt.ok && (len(addrs) != len(tt.in) || addrs[0] != common.HexToAddress(valid)) {
+			t.Errorf("%v: got %v", tt.in, addrs)
+		}
+	}
+
+	_, err := ParseAddresses([]string{"0x12", valid, "0x34"})
+	if err == nil || !strings.Contains(err.Error(), "0x12, 0x34") {
+		t.Errorf("got %v, want both invalid entries reported", err)
+	}
+}
@@ -0,0 +1,529 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ipcNode serves the eth methods used by Monitor, announcing tx once to
+// every newPendingTransactions subscriber.
+type ipcNode struct {
+	chainID *big.Int
+	tx      *types.Transaction
+	fetches atomic.Int32
+	// subFailures fail as many newPendingTransactions subscriptions, subs
+	// counts the others
+	subFailures atomic.Int32
+	subs        atomic.Int32
+	// alchemy is the filter of the last alchemy_pendingTransactions
+	// subscription, noAlchemy fails them
+	alchemy   atomic.Pointer[alchemyFilter]
+	noAlchemy bool
+}
+
+func (n *ipcNode) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(n.chainID)
+}
+
+func (n *ipcNode) BlockNumber() hexutil.Uint64 {
+	return 100
+}
+
+// GetTransactionByHash answers null for unknown hashes, a nil
+// *types.Transaction would not marshal.
+func (n *ipcNode) GetTransactionByHash(hash common.Hash) interface{} {
+	n.fetches.Add(1)
+	if hash == n.tx.Hash() {
+		return n.tx
+	}
+	return nil
+}
+
+func (n *ipcNode) NewPendingTransactions(ctx context.Context) (*rpc.Subscription, error) {
+	if n.subFailures.Add(-1) >= 0 {
+		return nil, errors.New("pool unavailable")
+	}
+	n.subs.Add(1)
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	go notifier.Notify(sub.ID, n.tx.Hash())
+	return sub, nil
+}
+
+// Alchemy_pendingTransactions announces the full tx once, as Alchemy does.
+func (n *ipcNode) Alchemy_pendingTransactions(ctx context.Context, filter alchemyFilter) (*rpc.Subscription, error) {
+	if n.noAlchemy {
+		return nil, errors.New("the method alchemy_pendingTransactions does not exist")
+	}
+	n.alchemy.Store(&filter)
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	go notifier.Notify(sub.ID, n.tx)
+	return sub, nil
+}
+
+func (n *ipcNode) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	go notifier.Notify(sub.ID, &types.Header{Number: big.NewInt(42), BaseFee: big.NewInt(7), Difficulty: big.NewInt(0)})
+	return sub, nil
+}
+
+// GetBalance answers 5 wei at block 42, the head of NewHeads, and 1 wei
+// otherwise.
+func (n *ipcNode) GetBalance(addr common.Address, block rpc.BlockNumberOrHash) *hexutil.Big {
+	if num, ok := block.Number(); ok && num == 42 {
+		return (*hexutil.Big)(big.NewInt(5))
+	}
+	return (*hexutil.Big)(big.NewInt(1))
+}
+
+// serveIPC serves node on a fresh IPC socket and returns its path.
+func serveIPC(t *testing.T, node *ipcNode) string {
+	// socket paths are limited to about a hundred bytes, keep it short
+	dir, err := os.MkdirTemp("", "ipc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "geth.ipc")
+
+	server := rpc.NewServer()
+	t.Cleanup(server.Stop)
+	if err := server.RegisterName("eth", node); err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go server.ServeListener(l)
+	return path
+}
+
+func TestRunOverIPC(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	chainID := big.NewInt(1337)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID),
+		&types.LegacyTx{Nonce: 1, To: &common.Address{1}, Gas: 21000, GasPrice: big.NewInt(1), Value: big.NewInt(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := serveIPC(t, &ipcNode{chainID: chainID, tx: tx})
+
+	m, err := NewMonitor(path, []common.Address{crypto.PubkeyToAddress(key.PublicKey)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if m.ChainID.Cmp(chainID) != 0 {
+		t.Errorf("chain ID %v, want %v", m.ChainID, chainID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	got := make(chan common.Hash, 1)
+	err = m.Run(ctx, func(tx *types.Transaction) {
+		got <- tx.Hash()
+		cancel()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case h := <-got:
+		if h != tx.Hash() {
+			t.Errorf("handled 0x%x, want 0x%x", h, tx.Hash())
+		}
+	default:
+		t.Fatal("pending tx not handled over IPC")
+	}
+}
+
+func TestRunHashesOverIPC(t *testing.T) {
+	tx := types.NewTransaction(1, common.Address{1}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	node := &ipcNode{chainID: big.NewInt(1337), tx: tx}
+	path := serveIPC(t, node)
+
+	// nobody is watched, the hash is handed over regardless
+	m, err := NewMonitor(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	got := make(chan common.Hash, 1)
+	err = m.RunHashes(ctx, func(h common.Hash) {
+		got <- h
+		cancel()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case h := <-got:
+		if h != tx.Hash() {
+			t.Errorf("handled 0x%x, want 0x%x", h, tx.Hash())
+		}
+	default:
+		t.Fatal("pending hash not handled")
+	}
+	if n := node.fetches.Load(); n != 0 {
+		t.Errorf("%d txs fetched", n)
+	}
+}
+
+func TestRunWatchHeads(t *testing.T) {
+	tx := types.NewTransaction(1, common.Address{1}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	path := serveIPC(t, &ipcNode{chainID: big.NewInt(1337), tx: tx})
+
+	m, err := NewMonitor(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	m.WatchHeads = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- m.Run(ctx, func(*types.Transaction) {}) }()
+
+	for m.head.latest() == nil && ctx.Err() == nil {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	head := m.Head()
+	if head == nil || head.Number.Int64() != 42 {
+		t.Fatalf("head %v, want block 42", head)
+	}
+	if fee := m.BaseFee(); fee == nil || fee.Int64() != 7 {
+		t.Errorf("base fee %v, want 7", fee)
+	}
+}
+
+func TestRunBatched(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	chainID := big.NewInt(1337)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID),
+		&types.LegacyTx{Nonce: 1, To: &common.Address{1}, Gas: 21000, GasPrice: big.NewInt(1), Value: big.NewInt(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := &ipcNode{chainID: chainID, tx: tx}
+	path := serveIPC(t, node)
+
+	m, err := NewMonitor(path, []common.Address{crypto.PubkeyToAddress(key.PublicKey)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	// an unknown hash in the batch comes back as null and is skipped
+	found, err := m.fetchBatch(context.Background(), []common.Hash{{9}, tx.Hash()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].tx.Hash() != tx.Hash() || len(found[0].raw) == 0 {
+		t.Fatalf("batch found %v, want only 0x%x", found, tx.Hash())
+	}
+
+	m.BatchWindow = 10 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	got := make(chan common.Hash, 1)
+	err = m.Run(ctx, func(tx *types.Transaction) {
+		got <- tx.Hash()
+		cancel()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case h := <-got:
+		if h != tx.Hash() {
+			t.Errorf("handled 0x%x, want 0x%x", h, tx.Hash())
+		}
+	default:
+		t.Fatal("pending tx not handled from a batch")
+	}
+}
+
+func TestRunRaw(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	chainID := big.NewInt(1337)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID),
+		&types.LegacyTx{Nonce: 1, To: &common.Address{1}, Gas: 21000, GasPrice: big.NewInt(1), Value: big.NewInt(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := serveIPC(t, &ipcNode{chainID: chainID, tx: tx})
+
+	m, err := NewMonitor(path, []common.Address{crypto.PubkeyToAddress(key.PublicKey)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	got := make(chan json.RawMessage, 1)
+	err = m.RunRaw(ctx, func(tx *types.Transaction, raw json.RawMessage) {
+		got <- raw
+		cancel()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var raw json.RawMessage
+	select {
+	case raw = <-got:
+	default:
+		t.Fatal("pending tx not handled")
+	}
+	var fields struct {
+		Hash common.Hash  `json:"hash"`
+		R    *hexutil.Big `json:"r"`
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		t.Fatalf("raw payload %s: %v", raw, err)
+	}
+	_, r, _ := tx.RawSignatureValues()
+	if fields.Hash != tx.Hash() || fields.R == nil || fields.R.ToInt().Cmp(r) != 0 {
+		t.Errorf("raw payload %s, want hash 0x%x and r %v", raw, tx.Hash(), r)
+	}
+}
+
+func TestWatchBalancesOverIPC(t *testing.T) {
+	path := serveIPC(t, &ipcNode{chainID: big.NewInt(1337), tx: types.NewTx(&types.LegacyTx{})})
+
+	watched := common.Address{7}
+	m, err := NewMonitor(path, []common.Address{watched})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	got := make(chan BalanceChange, 1)
+	err = m.WatchBalances(ctx, []common.Address{watched}, nil, func(c BalanceChange) {
+		got <- c
+		cancel()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case c := <-got:
+		if c.Address != watched || c.Block.Int64() != 42 || c.Old.Int64() != 1 || c.New.Int64() != 5 {
+			t.Errorf("got change %+v, want 1 to 5 wei at block 42", c)
+		}
+	default:
+		t.Fatal("balance change not handled")
+	}
+}
+
+func TestRunRetriesFirstSubscription(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	chainID := big.NewInt(1337)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID),
+		&types.LegacyTx{Nonce: 1, To: &common.Address{1}, Gas: 21000, GasPrice: big.NewInt(1), Value: big.NewInt(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := &ipcNode{chainID: chainID, tx: tx}
+	path := serveIPC(t, node)
+
+	m, err := NewMonitor(path, []common.Address{crypto.PubkeyToAddress(key.PublicKey)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	// a single attempt fails at once
+	node.subFailures.Store(1)
+	m.StartAttempts = 1
+	if err := m.Run(context.Background(), func(*types.Transaction) {}); err == nil {
+		t.Fatal("Run started without a subscription")
+	}
+
+	// a second one gets through
+	node.subFailures.Store(1)
+	m.StartAttempts = 2
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	got := make(chan common.Hash, 1)
+	err = m.Run(ctx, func(tx *types.Transaction) {
+		got <- tx.Hash()
+		cancel()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-got:
+	default:
+		t.Fatal("pending tx not handled after the retry")
+	}
+}
+
+func TestRunAlchemySubscription(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	chainID := big.NewInt(1337)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID),
+		&types.LegacyTx{Nonce: 1, To: &common.Address{1}, Gas: 21000, GasPrice: big.NewInt(1), Value: big.NewInt(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, noAlchemy := range []bool{false, true} {
+		node := &ipcNode{chainID: chainID, tx: tx, noAlchemy: noAlchemy}
+		path := serveIPC(t, node)
+
+		m, err := NewMonitor(path, []common.Address{from})
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.Provider = ProviderAlchemy
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		got := make(chan common.Hash, 1)
+		err = m.Run(ctx, func(tx *types.Transaction) {
+			got <- tx.Hash()
+			cancel()
+		})
+		cancel()
+		m.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case h := <-got:
+			if h != tx.Hash() {
+				t.Errorf("noAlchemy %v: handled 0x%x, want 0x%x", noAlchemy, h, tx.Hash())
+			}
+		default:
+			t.Fatalf("noAlchemy %v: pending tx not handled", noAlchemy)
+		}
+
+		fetches := node.fetches.Load()
+		if noAlchemy {
+			if fetches == 0 {
+				t.Error("fallback subscription did not fetch the tx")
+			}
+			continue
+		}
+		if fetches != 0 {
+			t.Errorf("%d fetches, want the tx of the subscription", fetches)
+		}
+		f := node.alchemy.Load()
+		if f == nil || len(f.FromAddress) != 1 || f.FromAddress[0] != from || len(f.ToAddress) != 0 || f.HashesOnly {
+			t.Errorf("filter %+v, want the txs from %v", f, from)
+		}
+	}
+}
+
+func TestDetectProvider(t *testing.T) {
+	for url, want := range map[string]string{
+		"wss://eth-mainnet.g.alchemy.com/v2/key": ProviderAlchemy,
+		"https://ALCHEMY.com/v2/key":             ProviderAlchemy,
+		"wss://mainnet.infura.io/ws/v3/key":      ProviderGeneric,
+		"wss://alchemy.com.example.org":          ProviderGeneric,
+		"/tmp/geth.ipc":                          ProviderGeneric,
+	} {
+		if got := DetectProvider(url); got != want {
+			t.Errorf("DetectProvider(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+func TestRunResubscribes(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	chainID := big.NewInt(1337)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID),
+		&types.LegacyTx{Nonce: 1, To: &common.Address{1}, Gas: 21000, GasPrice: big.NewInt(1), Value: big.NewInt(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, set := range map[string]func(*Monitor){
+		"interval": func(m *Monitor) { m.ResubInterval = 50 * time.Millisecond },
+		"idle":     func(m *Monitor) { m.ResubIdle = 50 * time.Millisecond },
+	} {
+		// every subscription announces the tx once, then stays silent
+		node := &ipcNode{chainID: chainID, tx: tx}
+		path := serveIPC(t, node)
+		m, err := NewMonitor(path, []common.Address{crypto.PubkeyToAddress(key.PublicKey)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.DedupSize = 0
+		set(m)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		var handled atomic.Int32
+		err = m.Run(ctx, func(*types.Transaction) {
+			if handled.Add(1) == 2 {
+				cancel()
+			}
+		})
+		cancel()
+		m.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if n := handled.Load(); n < 2 {
+			t.Errorf("%s: handled %d announcements, want one per subscription", name, n)
+		}
+		if n := node.subs.Load(); n < 2 {
+			t.Errorf("%s: %d subscriptions, want a renewed one", name, n)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	// DefaultKafkaBuffer is the number of records queued for the producer.
+	DefaultKafkaBuffer = 1024
+
+	// KafkaFlushTimeout bounds the wait for the queued records on Close.
+	KafkaFlushTimeout = 10 * time.Second
+)
+
+// messageWriter is the part of kafka.Writer used by Kafka.
+type messageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// Kafka publishes matched txs as JSON TxRecords to a Kafka topic, keyed by
+// their hash. Records are queued and produced in the background, a broker
+// falling behind or down loses records rather than holding up the matches,
+// Failed counts them.
+type Kafka struct {
+	Topic string
+
+	w      messageWriter
+	queue  chan kafka.Message
+	failed atomic.Uint64
+	slow   atomic.Bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	close  sync.Once
+
+	// mu guards queue against Publish after Close
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewKafka produces to topic on brokers, given as host:port, and queues up
+// to buffer records.
+func NewKafka(brokers []string, topic string, buffer int) *Kafka {
+	k := &Kafka{Topic: topic}
+	k.w = &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireOne,
+		BatchTimeout: 50 * time.Millisecond,
+		Async:        true,
+		Completion:   k.completed,
+	}
+	k.start(buffer)
+	return k
+}
+
+// start produces the queued records until Close.
+func (k *Kafka) start(buffer int) {
+	if buffer <= 0 {
+		buffer = DefaultKafkaBuffer
+	}
+	k.queue = make(chan kafka.Message, buffer)
+	k.ctx, k.cancel = context.WithCancel(context.Background())
+	k.done = make(chan struct{})
+
+	go func() {
+		defer close(k.done)
+		for msg := range k.queue {
+			// asynchronous, only the topic lookup may fail here
+			if err := k.w.WriteMessages(k.ctx, msg); err != nil {
+				k.completed([]kafka.Message{msg}, err)
+			}
+		}
+	}()
+}
+
+// completed counts and logs the records the producer failed to write.
+func (k *Kafka) completed(msgs []kafka.Message, err error) {
+	if err == nil {
+		return
+	}
+	k.failed.Add(uint64(len(msgs)))
+	kafkaFailed.Add(float64(len(msgs)))
+	slog.Warn("Kafka write failed", "topic", k.Topic, "records", len(msgs), "err", err)
+}
+
+// Handle makes k a Handler, it publishes the record of m.
+func (k *Kafka) Handle(ctx context.Context, m Match) error {
+	return k.Publish(m.Record)
+}
+
+// HandleBalance makes k a BalanceHandler, it publishes c keyed by its
+// address.
+func (k *Kafka) HandleBalance(ctx context.Context, c BalanceChange) error {
+	value, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	k.enqueue(kafka.Message{Key: []byte(c.Address.Hex()), Value: value})
+	return nil
+}
+
+// Publish queues rec for the producer without waiting on it. Once k is
+// closed records are dropped.
+func (k *Kafka) Publish(rec *TxRecord) error {
+	value, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	k.enqueue(kafka.Message{Key: []byte(rec.Hash.Hex()), Value: value})
+	return nil
+}
+
+// enqueue queues msg for the producer, dropping it if the queue is full or
+// k closed.
+func (k *Kafka) enqueue(msg kafka.Message) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.closed {
+		// a handler outliving the drain on shutdown
+		k.failed.Add(1)
+		kafkaFailed.Inc()
+		return
+	}
+
+	select {
+	case k.queue <- msg:
+		k.slow.Store(false)
+	default:
+		k.failed.Add(1)
+		kafkaFailed.Inc()
+		if !k.slow.Swap(true) {
+			slog.Warn("Kafka producer too slow, dropping records", "topic", k.Topic, "buffer", cap(k.queue))
+		}
+	}
+}
+
+// Failed returns the number of records lost so far.
+func (k *Kafka) Failed() uint64 {
+	return k.failed.Load()
+}
+
+// Close hands the queued records to the producer and flushes it, giving
+// up on a broker not taking them within KafkaFlushTimeout.
+func (k *Kafka) Close() error {
+	var err error
+	k.close.Do(func() {
+		k.mu.Lock()
+		k.closed = true
+		close(k.queue)
+		k.mu.Unlock()
+
+		timer := time.AfterFunc(KafkaFlushTimeout, k.cancel)
+		defer timer.Stop()
+		<-k.done
+
+		err = k.w.Close()
+		k.cancel()
+		if n := k.Failed(); n > 0 {
+			slog.Warn("Kafka missed records", "topic", k.Topic, "failed", n)
+		}
+	})
+	return err
+}
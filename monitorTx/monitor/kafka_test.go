@@ -0,0 +1,143 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/segmentio/kafka-go"
+)
+
+// fakeWriter collects the messages written, each failing with err, after
+// waiting for hold to close if set.
+type fakeWriter struct {
+	mu     sync.Mutex
+	msgs   []kafka.Message
+	err    error
+	hold   chan struct{}
+	closed bool
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if w.hold != nil {
+		select {
+		case <-w.hold:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.msgs = append(w.msgs, msgs...)
+	return w.err
+}
+
+func (w *fakeWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	return nil
+}
+
+func newTestKafka(w *fakeWriter, buffer int) *Kafka {
+	k := &Kafka{Topic: "txs", w: w}
+	k.start(buffer)
+	return k
+}
+
+func TestKafkaPublish(t *testing.T) {
+	w := &fakeWriter{}
+	k := newTestKafka(w, 0)
+
+	rec := NewTxRecord(valueTx(1, nil), common.Address{1})
+	if err := k.Handle(context.Background(), Match{Record: rec}); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !w.closed {
+		t.Error("writer not closed")
+	}
+	if len(w.msgs) != 1 {
+		t.Fatalf("%d messages, want 1", len(w.msgs))
+	}
+	if got := string(w.msgs[0].Key); got != rec.Hash.Hex() {
+		t.Errorf("key %s, want the tx hash %s", got, rec.Hash.Hex())
+	}
+	var got TxRecord
+	if err := json.Unmarshal(w.msgs[0].Value, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Hash != rec.Hash {
+		t.Errorf("published %v, want %v", got.Hash, rec.Hash)
+	}
+	if k.Failed() != 0 {
+		t.Errorf("%d failed, want 0", k.Failed())
+	}
+}
+
+func TestKafkaDropsWhenBlocked(t *testing.T) {
+	w := &fakeWriter{hold: make(chan struct{})}
+	k := newTestKafka(w, 2)
+
+	// the producer holds one record, the queue two more, the rest are
+	// dropped without blocking
+	for i := 0; i < 10; i++ {
+		if err := k.Publish(NewTxRecord(valueTx(1, nil), common.Address{1})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := k.Failed(); n < 7 || n > 8 {
+		t.Errorf("%d dropped, want 7 or 8", n)
+	}
+
+	close(w.hold)
+	k.Close()
+	if n := uint64(len(w.msgs)) + k.Failed(); n != 10 {
+		t.Errorf("%d written and failed, want 10", n)
+	}
+}
+
+func TestKafkaWriteError(t *testing.T) {
+	w := &fakeWriter{err: errors.New("unknown topic")}
+	k := newTestKafka(w, 0)
+	k.Publish(NewTxRecord(valueTx(1, nil), common.Address{1}))
+	k.Close()
+
+	if k.Failed() != 1 {
+		t.Errorf("%d failed, want 1", k.Failed())
+	}
+}
+
+func TestKafkaPublishAfterClose(t *testing.T) {
+	w := &fakeWriter{}
+	k := newTestKafka(w, 0)
+	k.Close()
+
+	if err := k.Publish(NewTxRecord(valueTx(1, nil), common.Address{1})); err != nil {
+		t.Fatal(err)
+	}
+	if k.Failed() != 1 || len(w.msgs) != 0 {
+		t.Errorf("%d failed and %d written, want the record dropped", k.Failed(), len(w.msgs))
+	}
+}
+
+func TestKafkaBalance(t *testing.T) {
+	w := &fakeWriter{}
+	k := newTestKafka(w, 0)
+	c := BalanceChange{Address: common.Address{1}, Block: big.NewInt(7), Old: big.NewInt(5), New: big.NewInt(2)}
+	if err := HandleBalance(context.Background(), k, c); err != nil {
+		t.Fatal(err)
+	}
+	k.Close()
+
+	if len(w.msgs) != 1 || string(w.msgs[0].Key) != c.Address.Hex() {
+		t.Errorf("wrote %v, want the change keyed by %s", w.msgs, c.Address.Hex())
+	}
+}
@@ -0,0 +1,66 @@
+package monitor
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// KeyEnv is the environment variable consulted when no key file is given.
+const KeyEnv = "MONITOR_PRIVKEY"
+
+// LoadKey reads a hex private key from path, or from KeyEnv if path is empty.
+// It returns a nil key and no error when neither is set.
+func LoadKey(path string) (*ecdsa.PrivateKey, error) {
+	var hexKey string
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		hexKey = string(data)
+	} else {
+		hexKey = os.Getenv(KeyEnv)
+	}
+
+	hexKey = strings.TrimSpace(hexKey)
+	if hexKey == "" {
+		return nil, nil
+	}
+
+	hexKey = strings.TrimPrefix(strings.TrimPrefix(hexKey, "0x"), "0X")
+	return crypto.HexToECDSA(hexKey)
+}
+
+// LoadKeystore decrypts a geth keystore JSON file with the password read
+// from passwordFile.
+func LoadKeystore(path, passwordFile string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var password string
+	if passwordFile != "" {
+		p, err := os.ReadFile(passwordFile)
+		if err != nil {
+			return nil, err
+		}
+		password = strings.TrimRight(string(p), "\r\n")
+	}
+
+	key, err := keystore.DecryptKey(data, password)
+	if errors.Is(err, keystore.ErrDecrypt) {
+		return nil, fmt.Errorf("wrong password for keystore %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return key.PrivateKey, nil
+}
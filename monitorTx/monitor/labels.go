@@ -0,0 +1,59 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Labels names known addresses, such as exchanges or your own wallets.
+type Labels map[common.Address]string
+
+// LoadLabels reads a JSON object mapping addresses to labels. Every key
+// must be an address and every label non-empty.
+func LoadLabels(path string) (Labels, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("labels %s: %v", path, err)
+	}
+
+	labels := make(Labels, len(raw))
+	var invalid []string
+	for s, label := range raw {
+		if !common.IsHexAddress(s) || strings.TrimSpace(label) == "" {
+			invalid = append(invalid, s)
+			continue
+		}
+		labels[common.HexToAddress(s)] = strings.TrimSpace(label)
+	}
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return nil, fmt.Errorf("labels %s: invalid address or empty label: %s", path, strings.Join(invalid, ", "))
+	}
+	return labels, nil
+}
+
+// Name returns the label of addr followed by its short form, such as
+// "Treasury (0x003b…53eA)", or the full address if it has no label.
+func (l Labels) Name(addr common.Address) string {
+	label, ok := l[addr]
+	if !ok {
+		return addr.Hex()
+	}
+	return fmt.Sprintf("%s (%s)", label, ShortAddress(addr))
+}
+
+// ShortAddress abbreviates addr to its first and last four hex digits.
+func ShortAddress(addr common.Address) string {
+	h := addr.Hex()
+	return h[:6] + "…" + h[len(h)-4:]
+}
@@ -0,0 +1,52 @@
+package monitor
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestLoadLabels(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "labels.json")
+	if err := os.WriteFile(path, []byte(`{"0x003be5Df5FeF651EF0C59cD175c73ca1415f53eA": " Treasury "}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	labels, err := LoadLabels(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	known := common.HexToAddress("0x003be5Df5FeF651EF0C59cD175c73ca1415f53eA")
+	if got, want := labels.Name(known), "Treasury (0x003b…53eA)"; got != want {
+		t.Errorf("Name(known) = %q, want %q", got, want)
+	}
+	unknown := common.Address{1}
+	if got := labels.Name(unknown); got != unknown.Hex() {
+		t.Errorf("Name(unknown) = %q, want the address", got)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"0x1234": "short", "0x003be5Df5FeF651EF0C59cD175c73ca1415f53eA": ""}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadLabels(bad); err == nil || !strings.Contains(err.Error(), "0x1234") {
+		t.Errorf("invalid labels loaded, err %v", err)
+	}
+}
+
+func TestTxRecordLabel(t *testing.T) {
+	from, to := common.Address{1}, common.Address{2}
+	tx := types.NewTransaction(0, to, big.NewInt(0), 21000, big.NewInt(1), nil)
+
+	rec := NewTxRecord(tx, from)
+	rec.Label(Labels{to: "Exchange"})
+	if rec.FromLabel != "" || rec.ToLabel != "Exchange" {
+		t.Errorf("labels %q, %q, want only to labelled", rec.FromLabel, rec.ToLabel)
+	}
+}
@@ -0,0 +1,69 @@
+package monitor
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyInterval is how often Run logs the average fetch latency.
+const DefaultLatencyInterval = time.Minute
+
+// latencyWindow sums up the fetch latencies since it was last taken.
+type latencyWindow struct {
+	mu    sync.Mutex
+	n     int
+	total time.Duration
+	max   time.Duration
+}
+
+func (w *latencyWindow) observe(d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.n++
+	w.total += d
+	if d > w.max {
+		w.max = d
+	}
+}
+
+// take returns the count, average and maximum of the latencies observed
+// since the last take and starts over.
+func (w *latencyWindow) take() (n int, avg, max time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n, max = w.n, w.max
+	if n > 0 {
+		avg = w.total / time.Duration(n)
+	}
+	w.n, w.total, w.max = 0, 0, 0
+	return n, avg, max
+}
+
+// observeLatency records a tx fetched after its hash arrived at seen.
+func (m *Monitor) observeLatency(seen time.Time) {
+	d := time.Since(seen)
+	fetchLatency.Observe(d.Seconds())
+	m.latency.observe(d)
+}
+
+// logLatency logs the average and maximum fetch latency of every
+// LatencyInterval in which txs were fetched. A rising latency points to a
+// slow or rate limiting provider. It returns once ctx is done.
+func (m *Monitor) logLatency(ctx context.Context) {
+	ticker := time.NewTicker(m.LatencyInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if n, avg, max := m.latency.take(); n > 0 {
+			slog.Info("Fetch latency", "interval", m.LatencyInterval, "fetched", n, "avg", avg, "max", max)
+		}
+	}
+}
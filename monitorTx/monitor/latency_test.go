@@ -0,0 +1,26 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyWindow(t *testing.T) {
+	var w latencyWindow
+	if n, _, _ := w.take(); n != 0 {
+		t.Fatalf("empty window took %d", n)
+	}
+
+	for _, d := range []time.Duration{10 * time.Millisecond, 30 * time.Millisecond, 20 * time.Millisecond} {
+		w.observe(d)
+	}
+	if n, avg, max := w.take(); n != 3 || avg != 20*time.Millisecond || max != 30*time.Millisecond {
+		t.Errorf("took %d, avg %v, max %v, want 3, 20ms, 30ms", n, avg, max)
+	}
+
+	// taking starts over
+	w.observe(5 * time.Millisecond)
+	if n, avg, max := w.take(); n != 1 || avg != 5*time.Millisecond || max != 5*time.Millisecond {
+		t.Errorf("took %d, avg %v, max %v after the reset", n, avg, max)
+	}
+}
@@ -0,0 +1,71 @@
+package monitor
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Metrics are registered with the default prometheus registry.
+var (
+	hashesSeen = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "monitor_pending_hashes_total",
+		Help: "Pending tx hashes received from the subscription.",
+	})
+	txsFetched = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "monitor_txs_fetched_total",
+		Help: "Pending txs fetched by hash.",
+	})
+	fetchErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "monitor_fetch_errors_total",
+		Help: "Failed TransactionByHash calls.",
+	})
+	matches = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "monitor_matches_total",
+		Help: "Txs handed to the handler.",
+	})
+	replacements = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "monitor_replacements_total",
+		Help: "Matched txs of watched senders reusing a nonce of an earlier one.",
+	})
+	fetchLatency = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "monitor_fetch_latency_seconds",
+		Help:    "Time from receiving a pending tx hash until its tx is fetched.",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+	})
+	rpcErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "monitor_rpc_errors_total",
+		Help: "Failed RPC calls by call and error class.",
+	}, []string{"call", "class"})
+	processResults = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "monitor_process_total",
+		Help: "Process invocations by result.",
+	}, []string{"result"})
+	fetchesInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "monitor_fetches_in_flight",
+		Help: "TransactionByHash calls currently running.",
+	})
+	subBacklog = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "monitor_subscription_backlog",
+		Help: "Pending tx hashes queued for fetching.",
+	})
+	socketDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "monitor_socket_dropped_total",
+		Help: "Records not sent to a -socket reader too slow to take them.",
+	})
+	kafkaFailed = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "monitor_kafka_failed_total",
+		Help: "Records lost on their way to -kafka-topic, dropped or failed to write.",
+	})
+	hashesDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "monitor_hashes_dropped_total",
+		Help: "Pending tx hashes received with a full queue, approximating the dropped ones.",
+	})
+)
+
+func observeProcess(err error) {
+	if err != nil {
+		processResults.WithLabelValues("failure").Inc()
+	} else {
+		processResults.WithLabelValues("success").Inc()
+	}
+}
@@ -0,0 +1,1057 @@
+// Package monitor watches the pending transaction pool of an Ethereum node
+// for transactions sent by, or to, a set of designated addresses.
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"math/rand"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/time/rate"
+)
+
+// MatchedMsg is the message matched txs are logged with.
+const MatchedMsg = "Matched tx"
+
+// Match modes select which side of a tx is compared with the watched addresses.
+const (
+	MatchFrom   = "from"
+	MatchTo     = "to"
+	MatchEither = "either"
+)
+
+// Reconnect delays double from minBackoff up to MaxBackoff.
+const (
+	minBackoff        = time.Second
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// DefaultStartAttempts is how often the first connection and subscription
+// are tried before giving up.
+const DefaultStartAttempts = 10
+
+const (
+	// DefaultDrainTimeout bounds the wait for in-flight work on shutdown.
+	DefaultDrainTimeout = 10 * time.Second
+
+	// DefaultConcurrency bounds the parallel TransactionByHash calls.
+	DefaultConcurrency = 64
+
+	// DefaultFetchTimeout bounds a single TransactionByHash call.
+	DefaultFetchTimeout = 5 * time.Second
+
+	// DefaultSubBuffer and DefaultTxBuffer size the queues of pending
+	// hashes and of fetched txs.
+	DefaultSubBuffer = 1024
+	DefaultTxBuffer  = 1024
+)
+
+// MatchTx reports the watched address involved in a tx according to mode.
+// A nil to (contract creation) never matches a recipient.
+func MatchTx(mode string, senders, recipients map[common.Address]struct{}, from common.Address, to *common.Address) (common.Address, bool) {
+	if mode == MatchFrom || mode == MatchEither {
+		if _, ok := senders[from]; ok {
+			return from, true
+		}
+	}
+
+	if (mode == MatchTo || mode == MatchEither) && to != nil {
+		if _, ok := recipients[*to]; ok {
+			return *to, true
+		}
+	}
+
+	return common.Address{}, false
+}
+
+// ErrZeroChainID is returned for a replay protected tx claiming chain ID 0.
+var ErrZeroChainID = errors.New("replay protected tx with chain ID 0")
+
+// Sender recovers the address that signed tx on chainID, accepting every tx
+// type. A nil chainID falls back to the chain ID carried by tx.
+func Sender(chainID *big.Int, tx *types.Transaction) (common.Address, error) {
+	if chainID == nil || chainID.Sign() == 0 {
+		chainID = tx.ChainId()
+	}
+	// without any chain ID only pre EIP-155 txs can be recovered
+	if chainID.Sign() == 0 {
+		if tx.Protected() {
+			return common.Address{}, ErrZeroChainID
+		}
+		return types.Sender(types.HomesteadSigner{}, tx)
+	}
+	return types.Sender(types.LatestSignerForChainID(chainID), tx)
+}
+
+// Monitor subscribes to the pending transactions of a node and hands the
+// ones involving a watched address to a handler.
+type Monitor struct {
+	// URL is the endpoint currently connected to, taken from Pool.
+	URL  string
+	Pool *EndpointPool
+
+	// Senders and Recipients are the watched addresses, compared with the
+	// from and to of a tx according to Match.
+	Senders    map[common.Address]struct{}
+	Recipients map[common.Address]struct{}
+	Match      string
+
+	// Prefixes are watched too, matching every address starting with one
+	// of them on the side picked by Match.
+	Prefixes []AddressPrefix
+
+	// Filters must all pass for a matched tx to reach the handler.
+	Filters []Filter
+
+	// Provider selects the pending tx subscription, one of the Provider
+	// constants, "" detecting it from URL.
+	Provider string
+
+	// ExpectBlobs, set when Filters look for blob txs, warns if none shows
+	// up among the first pending txs since not every node announces them.
+	ExpectBlobs bool
+
+	// ABI, if set, decodes the input of matched txs in the log.
+	ABI *ContractABI
+
+	// DataLimit limits the input logged of a matched tx, see FormatData.
+	DataLimit int
+
+	// Price, if set, adds the USD worth of matched txs to the log.
+	Price *PriceFeed
+
+	// Labels, if set, name the known addresses in the log.
+	Labels Labels
+
+	// ChainID is detected from the node on connect.
+	ChainID *big.Int
+
+	// DrainTimeout bounds how long Run waits for in-flight fetches and
+	// handlers once ctx is done. Zero waits forever.
+	DrainTimeout time.Duration
+
+	// Concurrency bounds the parallel tx fetches, zero means unbounded.
+	// Hashes arriving while all fetches are busy queue in the subscription.
+	Concurrency int
+
+	// FetchTimeout bounds every tx fetch, zero means no timeout.
+	FetchTimeout time.Duration
+
+	// SubBuffer is how many pending hashes queue for fetching and TxBuffer
+	// how many fetched txs queue for matching, DefaultSubBuffer and
+	// DefaultTxBuffer if zero. Every slot of a queue costs memory once
+	// filled, a hash about a hundred bytes and a tx a few hundred plus its
+	// input, while a full hash queue backs up into the subscription,
+	// which is dropped once that overflows too.
+	SubBuffer int
+	TxBuffer  int
+
+	// BatchWindow, if set, collects the pending hashes arriving within it
+	// and fetches them in a single batch call on the current connection,
+	// instead of one call per hash spread over Pool.
+	BatchWindow time.Duration
+
+	// RPS limits the tx fetches per second shared by all fetches, zero
+	// means unlimited.
+	RPS float64
+
+	// DedupSize is how many recent pending hashes are remembered so that
+	// re-announced hashes aren't fetched twice, zero disables it.
+	DedupSize int
+
+	// PollInterval paces polling when URL is http(s) and cannot subscribe.
+	PollInterval time.Duration
+
+	// MaxBackoff caps the delay between reconnect attempts.
+	MaxBackoff time.Duration
+
+	// WatchHeads also subscribes to new block headers, keeping Head current
+	// without polling for it.
+	WatchHeads bool
+
+	// StartAttempts is how often the first subscription is tried, with the
+	// backoff of a reconnect, before Run fails. Zero retries until ctx is
+	// done.
+	StartAttempts int
+
+	// Heartbeat is the quiet period after which Run logs that no pending
+	// tx arrived, zero disables it.
+	Heartbeat time.Duration
+
+	// ResubInterval, if set, renews the subscriptions that often, for
+	// providers that stop delivering without failing them. ResubIdle
+	// redials once no pending tx arrived for that long.
+	ResubInterval time.Duration
+	ResubIdle     time.Duration
+
+	// LatencyInterval is how often Run logs the average time from receiving
+	// a pending hash until its tx is fetched, zero disables it. The latency
+	// is measured regardless for the fetch latency histogram.
+	LatencyInterval time.Duration
+
+	// NonceGaps looks up the pending nonce of a watched sender on its
+	// matches and warns of a tx skipping past it, an earlier one being
+	// stuck. The nonce is cached for about a block.
+	NonceGaps bool
+
+	// ErrorInterval is how often Run logs the RPC errors so far by type,
+	// zero only logs them when it returns.
+	ErrorInterval time.Duration
+
+	// received counts the pending hashes or polled txs, for the heartbeat.
+	received atomic.Uint64
+
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	rpc    *rpc.Client
+	client TxClient
+
+	head      headCache
+	sent      sentNonces
+	nonceGaps nonceGaps
+	latency   latencyWindow
+	blobs     blobCheck
+
+	statsMu sync.Mutex
+	stats   map[common.Address]*Stats
+}
+
+// NewMonitor connects to endpoint and watches txs sent by addrs. A ws(s)
+// url or an IPC socket path subscribes to pending txs, an http(s) url is
+// polled instead.
+func NewMonitor(endpoint string, addrs []common.Address) (*Monitor, error) {
+	pool, err := NewEndpointPool([]string{endpoint}, PolicyFailover)
+	if err != nil {
+		return nil, err
+	}
+	return NewMonitorPool(pool, addrs)
+}
+
+// NewMonitorPool connects to the first reachable endpoint of pool, starting
+// with its primary, and watches txs sent by addrs.
+func NewMonitorPool(pool *EndpointPool, addrs []common.Address) (*Monitor, error) {
+	set := AddressSet(addrs)
+	m := &Monitor{
+		Pool:       pool,
+		Senders:    set,
+		Recipients: set,
+		Match:      MatchFrom,
+
+		DrainTimeout:    DefaultDrainTimeout,
+		Concurrency:     DefaultConcurrency,
+		FetchTimeout:    DefaultFetchTimeout,
+		SubBuffer:       DefaultSubBuffer,
+		TxBuffer:        DefaultTxBuffer,
+		PollInterval:    DefaultPollInterval,
+		DedupSize:       DefaultDedupSize,
+		Heartbeat:       DefaultHeartbeat,
+		LatencyInterval: DefaultLatencyInterval,
+		MaxBackoff:      DefaultMaxBackoff,
+		StartAttempts:   DefaultStartAttempts,
+		DataLimit:       DefaultDataLimit,
+	}
+
+	var err error
+	for i := 0; i < len(pool.endpoints); i++ {
+		if i > 0 {
+			slog.Warn("Connect failed, trying the next endpoint", "endpoint", m.URL, "err", err)
+			pool.Rotate()
+		}
+		m.URL = pool.Primary()
+		if err = m.connect(); err == nil {
+			pool.DialFetchers(context.Background())
+			return m, nil
+		}
+	}
+	return nil, err
+}
+
+// ConnectMonitorPool is NewMonitorPool retrying with the backoff of a
+// reconnect while no endpoint of pool is reachable, at most attempts times
+// unless that is zero.
+func ConnectMonitorPool(ctx context.Context, pool *EndpointPool, addrs []common.Address, attempts int) (*Monitor, error) {
+	backoff := minBackoff
+	for attempt := 1; ; attempt++ {
+		m, err := NewMonitorPool(pool, addrs)
+		if err == nil {
+			return m, nil
+		}
+		if attempts > 0 && attempt >= attempts {
+			return nil, fmt.Errorf("connect: giving up after %d attempts: %w", attempt, err)
+		}
+
+		delay := jitter(backoff)
+		slog.Warn("Connect failed, retrying", "endpoint", pool.Primary(), "delay", delay, "attempt", attempt, "err", err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		backoff = min(backoff*2, DefaultMaxBackoff)
+	}
+}
+
+// dial connects to URL with the header of Pool.
+func (m *Monitor) dial(ctx context.Context) (*rpc.Client, error) {
+	var header http.Header
+	if m.Pool != nil {
+		header = m.Pool.Header
+	}
+	return dial(ctx, m.URL, header)
+}
+
+// connect dials URL and detects the chain ID.
+func (m *Monitor) connect() error {
+	rpccli, err := m.dial(context.Background())
+	if err != nil {
+		return err
+	}
+
+	chainID, err := ethclient.NewClient(rpccli).ChainID(context.Background())
+	if err != nil {
+		rpccli.Close()
+		return err
+	}
+	m.setClient(rpccli)
+	m.ChainID = chainID
+	slog.Info("Connected", "endpoint", m.URL, "chainID", m.ChainID)
+	return nil
+}
+
+// Client returns the client of the current connection.
+func (m *Monitor) Client() TxClient {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.client
+}
+
+// Close disconnects from the node.
+func (m *Monitor) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.rpc != nil {
+		m.rpc.Close()
+	}
+	if m.Pool != nil {
+		m.Pool.Close()
+	}
+}
+
+// fetcher returns the client for the next tx fetch and a func reporting
+// its result to the pool.
+func (m *Monitor) fetcher() (TxClient, func(error)) {
+	if m.Pool == nil {
+		return m.Client(), func(error) {}
+	}
+	return m.Pool.fetcher(m.Client())
+}
+
+func (m *Monitor) setClient(rpccli *rpc.Client) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.rpc != nil {
+		m.rpc.Close()
+	}
+	m.rpc = rpccli
+	m.client = ethclient.NewClient(rpccli)
+}
+
+func (m *Monitor) rpcClient() *rpc.Client {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rpc
+}
+
+// subscriptions are those of Run on the current connection.
+type subscriptions struct {
+	pending *rpc.ClientSubscription
+	// heads is nil unless WatchHeads is set
+	heads *rpc.ClientSubscription
+}
+
+// errs returns the error channels of the subscriptions, nil for a missing
+// one so that it never fires.
+func (s *subscriptions) errs() (pending, heads <-chan error) {
+	if s.heads != nil {
+		heads = s.heads.Err()
+	}
+	return s.pending.Err(), heads
+}
+
+func (s *subscriptions) unsubscribe() {
+	s.pending.Unsubscribe()
+	if s.heads != nil {
+		s.heads.Unsubscribe()
+	}
+}
+
+// feeds are the channels the subscriptions of run deliver to.
+type feeds struct {
+	hashes chan<- string
+	// txs takes the full txs of a provider subscription, see Provider
+	txs   chan<- json.RawMessage
+	heads chan<- *types.Header
+}
+
+// subscribe subscribes to new pending txs and, with WatchHeads, to new block
+// headers.
+func (m *Monitor) subscribe(ctx context.Context, f feeds) (*subscriptions, error) {
+	rpccli := m.rpcClient()
+	pending, err := m.subscribePending(ctx, rpccli, f)
+	if err != nil {
+		return nil, err
+	}
+	subs := &subscriptions{pending: pending}
+
+	if m.WatchHeads {
+		if subs.heads, err = rpccli.EthSubscribe(ctx, f.heads, "newHeads"); err != nil {
+			pending.Unsubscribe()
+			return nil, err
+		}
+	}
+	return subs, nil
+}
+
+// reconnect redials the node with exponential backoff until it succeeds,
+// at most attempts times unless that is zero. Every delay is jittered so
+// that instances sharing a provider don't all retry at once. It gives up
+// with ctx.Err() once ctx is done.
+func (m *Monitor) reconnect(ctx context.Context, f feeds, attempts int) (*subscriptions, error) {
+	maxBackoff := m.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+	backoff := min(minBackoff, maxBackoff)
+
+	for attempt := 1; ; attempt++ {
+		delay := jitter(backoff)
+		slog.Info("Reconnecting", "endpoint", m.URL, "delay", delay, "attempt", attempt)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		rpccli, err := m.dial(ctx)
+		if err == nil {
+			m.setClient(rpccli)
+
+			var subs *subscriptions
+			if subs, err = m.subscribe(ctx, f); err == nil {
+				slog.Info("Reconnected", "endpoint", m.URL, "attempts", attempt)
+				return subs, nil
+			}
+		}
+		slog.Warn("Reconnect failed", "endpoint", m.URL, "err", err)
+		if attempts > 0 && attempt >= attempts {
+			return nil, fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+
+		if m.Pool != nil && len(m.Pool.endpoints) > 1 && attempt%failoverAfter == 0 {
+			m.URL = m.Pool.Rotate()
+			slog.Warn("Failing over", "endpoint", m.URL)
+		}
+
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// jitter returns a random delay in (0, d], the "full jitter" of d. The
+// global source is seeded per process.
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d))) + 1
+}
+
+// drain waits for in-flight fetches and handlers, up to DrainTimeout.
+func (m *Monitor) drain() {
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	var timeout <-chan time.Time
+	if m.DrainTimeout > 0 {
+		timeout = time.After(m.DrainTimeout)
+	}
+
+	select {
+	case <-done:
+	case <-timeout:
+		slog.Warn("Gave up waiting for in-flight work", "timeout", m.DrainTimeout)
+	}
+}
+
+// Run watches pending transactions until ctx is done, invoking handler in
+// its own goroutine for every matched tx. Cancelling ctx aborts pending
+// fetches, Run then waits for running handlers before it returns.
+// Over http(s) the node is polled instead, see poll.
+func (m *Monitor) Run(ctx context.Context, handler func(*types.Transaction)) error {
+	return m.run(ctx, func(tx *types.Transaction, _ json.RawMessage) { handler(tx) }, nil, false)
+}
+
+// RawHandler handles a matched tx along with the raw result of the
+// eth_getTransactionByHash call it was decoded from.
+type RawHandler func(tx *types.Transaction, raw json.RawMessage)
+
+// RunRaw is Run handing handler the raw JSON of every tx too, giving access
+// to the fields types.Transaction drops. The payload is whatever the
+// provider returns, so its extra fields and their encoding differ between
+// nodes. Polled txs carry no payload, raw is nil then, and the raw fetches
+// always go to the primary endpoint.
+func (m *Monitor) RunRaw(ctx context.Context, handler RawHandler) error {
+	return m.run(ctx, handler, nil, true)
+}
+
+// RunHashes is Run without fetching the txs: every new pending hash is
+// handed to handler as is. Without the tx its sender, recipient and value
+// are unknown, so neither the watched addresses nor Filters apply.
+func (m *Monitor) RunHashes(ctx context.Context, handler func(common.Hash)) error {
+	return m.run(ctx, nil, handler, false)
+}
+
+// fetchedTx is a tx fetched by run, raw is its JSON if it was kept.
+type fetchedTx struct {
+	tx  *types.Transaction
+	raw json.RawMessage
+}
+
+// run implements Run and RunRaw, or RunHashes when onHash is set. Only with
+// wantRaw are the raw payloads fetched.
+func (m *Monitor) run(ctx context.Context, handler RawHandler, onHash func(common.Hash), wantRaw bool) error {
+	subBuffer, txBuffer := m.SubBuffer, m.TxBuffer
+	if subBuffer <= 0 {
+		subBuffer = DefaultSubBuffer
+	}
+	if txBuffer <= 0 {
+		txBuffer = DefaultTxBuffer
+	}
+	subch := make(chan string, subBuffer)
+	txs := make(chan fetchedTx, txBuffer)
+	heads := make(chan *types.Header, 16)
+	full := make(chan json.RawMessage, subBuffer)
+	subFeeds := feeds{hashes: subch, txs: full, heads: heads}
+
+	var subs *subscriptions
+	var subErr, headErr <-chan error
+
+	if isHTTP(m.URL) {
+		slog.Info("Polling", "endpoint", m.URL, "interval", m.PollInterval)
+
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.poll(ctx, txs)
+		}()
+	} else {
+		var err error
+		if subs, err = m.subscribe(ctx, subFeeds); err != nil && m.StartAttempts != 1 {
+			// the provider may be down for a moment at startup
+			slog.Warn("Subscribe failed, retrying", "endpoint", m.URL, "err", err)
+			if subs, err = m.reconnect(ctx, subFeeds, m.StartAttempts-1); ctx.Err() != nil {
+				return nil
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("subscribe: %w", err)
+		}
+		subErr, headErr = subs.errs()
+	}
+	defer logRPCErrors()
+	defer m.drain()
+
+	if !m.WatchHeads {
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.watchHead(ctx)
+		}()
+	}
+	if m.Heartbeat > 0 {
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.heartbeat(ctx)
+		}()
+	}
+	if m.LatencyInterval > 0 && onHash == nil {
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.logLatency(ctx)
+		}()
+	}
+	if m.ErrorInterval > 0 {
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			logRPCErrorsEvery(ctx, m.ErrorInterval)
+		}()
+	}
+
+	var sem chan struct{}
+	if m.Concurrency > 0 {
+		sem = make(chan struct{}, m.Concurrency)
+	}
+
+	limiter := rate.NewLimiter(rate.Inf, 0)
+	if m.RPS > 0 {
+		limiter = rate.NewLimiter(rate.Limit(m.RPS), 1)
+	}
+
+	backlog := &backlogWatch{capacity: cap(subch)}
+
+	var recent *hashRing
+	if m.DedupSize > 0 {
+		recent = newHashRing(m.DedupSize)
+	}
+
+	// hashes of the current batch and when they arrived, fetched once
+	// flush fires
+	var batch []common.Hash
+	var batchSeen []time.Time
+	var flush <-chan time.Time
+
+	// startBatch fetches hashes with a single batch call in its own
+	// goroutine, taking one fetch slot and one request of the rate limit.
+	startBatch := func(hashes []common.Hash, seen []time.Time) {
+		if sem != nil {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+
+			var found []fetchedTx
+			err := limiter.Wait(ctx)
+			if err == nil {
+				fetchesInFlight.Inc()
+				found, err = m.fetchBatch(ctx, hashes)
+				fetchesInFlight.Dec()
+			}
+
+			// release before handing over, the loop may be waiting on sem
+			if sem != nil {
+				<-sem
+			}
+
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.Warn("Batch fetch failed", "hashes", len(hashes), "err", err)
+				}
+				fetchErrors.Inc()
+				observeRPCError(CallFetch, err)
+				return
+			}
+			arrived := make(map[common.Hash]time.Time, len(hashes))
+			for i, h := range hashes {
+				arrived[h] = seen[i]
+			}
+			for _, f := range found {
+				m.observeLatency(arrived[f.tx.Hash()])
+				if !wantRaw {
+					f.raw = nil
+				}
+				select {
+				case txs <- f:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	// resubscribe replaces subs after one of them failed, the other shares
+	// the connection and goes too. It returns false once ctx is done.
+	resubscribe := func(msg string, err error) bool {
+		slog.Error(msg, "endpoint", m.URL, "err", err)
+		subs.unsubscribe()
+
+		if subs, err = m.reconnect(ctx, subFeeds, 0); err != nil {
+			return false
+		}
+		subErr, headErr = subs.errs()
+		return true
+	}
+
+	// renew replaces subs while they seem fine, on the same connection
+	// unless subscribing there fails. It returns false once ctx is done.
+	renew := func() bool {
+		slog.Info("Resubscribing", "endpoint", m.URL, "interval", m.ResubInterval)
+		subs.unsubscribe()
+
+		var err error
+		if subs, err = m.subscribe(ctx, subFeeds); err != nil {
+			slog.Warn("Resubscribe failed", "endpoint", m.URL, "err", err)
+			if subs, err = m.reconnect(ctx, subFeeds, 0); err != nil {
+				return false
+			}
+		}
+		subErr, headErr = subs.errs()
+		return true
+	}
+
+	var resubTick, idleTick <-chan time.Time
+	if m.ResubInterval > 0 {
+		t := time.NewTicker(m.ResubInterval)
+		defer t.Stop()
+		resubTick = t.C
+	}
+	// when the last pending tx arrived, checked a few times per ResubIdle
+	lastTx := time.Now()
+	if m.ResubIdle > 0 {
+		t := time.NewTicker(max(m.ResubIdle/4, time.Millisecond))
+		defer t.Stop()
+		idleTick = t.C
+	}
+
+	for {
+		select {
+
+		case <-ctx.Done():
+			if subs != nil {
+				subs.unsubscribe()
+			}
+			m.logStats()
+			return nil
+
+		case hash := <-subch:
+			seen := time.Now()
+			lastTx = seen
+			hashesSeen.Inc()
+			m.received.Add(1)
+			// count the hash just taken off the queue
+			backlog.observe(len(subch)+1, seen)
+			bytesHash, err := HexStringToTxHash(hash)
+
+			if err != nil {
+				continue
+			}
+
+			if recent != nil && !recent.add(bytesHash) {
+				slog.Debug("Tx announced again, skipped", "hash", bytesHash)
+				continue
+			}
+
+			if onHash != nil {
+				m.dispatchHash(bytesHash, onHash)
+				continue
+			}
+
+			if m.BatchWindow > 0 {
+				batchSeen = append(batchSeen, seen)
+				if batch = append(batch, bytesHash); len(batch) == 1 {
+					flush = time.After(m.BatchWindow)
+				}
+				if len(batch) >= maxBatchSize {
+					startBatch(batch, batchSeen)
+					batch, batchSeen, flush = nil, nil, nil
+				}
+				continue
+			}
+
+			if sem != nil {
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+					continue
+				}
+			}
+
+			client, report := m.fetcher()
+			m.wg.Add(1)
+			go func(h common.Hash, client TxClient, results chan<- fetchedTx) {
+				defer m.wg.Done()
+
+				var f fetchedTx
+				err := limiter.Wait(ctx)
+				if err == nil {
+					fetchesInFlight.Inc()
+					if wantRaw {
+						f.tx, f.raw, err = fetchRawTx(ctx, m.rpcClient(), h, m.FetchTimeout)
+					} else {
+						f.tx, err = fetchTx(ctx, client, h, m.FetchTimeout)
+						report(err)
+					}
+					fetchesInFlight.Dec()
+				}
+
+				// release before handing over, the loop may be waiting on sem
+				if sem != nil {
+					<-sem
+				}
+
+				if err != nil {
+					if IsRateLimited(err) {
+						slog.Warn("Rate limited by provider", "hash", h, "err", err)
+					} else if errors.Is(err, context.DeadlineExceeded) {
+						slog.Warn("Timed out fetching tx", "hash", h, "timeout", m.FetchTimeout)
+					}
+					fetchErrors.Inc()
+					observeRPCError(CallFetch, err)
+					return
+				}
+				txsFetched.Inc()
+				m.observeLatency(seen)
+
+				select {
+				case results <- f:
+				case <-ctx.Done():
+				}
+			}(bytesHash, client, txs)
+
+		case <-flush:
+			startBatch(batch, batchSeen)
+			batch, batchSeen, flush = nil, nil, nil
+
+		case h := <-heads:
+			m.head.set(h)
+
+		case err := <-subErr:
+			if !resubscribe("Subscription dropped", err) {
+				m.logStats()
+				return nil
+			}
+
+		case err := <-headErr:
+			if !resubscribe("Head subscription dropped", err) {
+				m.logStats()
+				return nil
+			}
+
+		case <-resubTick:
+			if !renew() {
+				m.logStats()
+				return nil
+			}
+
+		case <-idleTick:
+			if idle := time.Since(lastTx); idle >= m.ResubIdle {
+				if !resubscribe("Subscription silent", fmt.Errorf("no pending tx for %v", idle.Round(time.Millisecond))) {
+					m.logStats()
+					return nil
+				}
+				lastTx = time.Now()
+			}
+
+		case raw := <-full:
+			// a provider subscription hands over the tx itself
+			lastTx = time.Now()
+			hashesSeen.Inc()
+			m.received.Add(1)
+			tx, err := decodeRawTx(raw)
+			if err != nil {
+				slog.Debug("Undecodable pending tx", "err", err)
+				continue
+			}
+			if recent != nil && !recent.add(tx.Hash()) {
+				slog.Debug("Tx announced again, skipped", "hash", tx.Hash())
+				continue
+			}
+			txsFetched.Inc()
+			if !wantRaw {
+				raw = nil
+			}
+			if onHash != nil {
+				m.dispatchHash(tx.Hash(), onHash)
+				continue
+			}
+			m.dispatch(ctx, tx, func(tx *types.Transaction) { handler(tx, raw) })
+
+		case f := <-txs:
+			if onHash != nil {
+				m.dispatchHash(f.tx.Hash(), onHash)
+				continue
+			}
+			m.dispatch(ctx, f.tx, func(tx *types.Transaction) { handler(tx, f.raw) })
+		}
+	}
+}
+
+// txFetcher is the part of TxClient used to fetch pending txs.
+type txFetcher interface {
+	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+}
+
+// fetchTx fetches the tx with hash h, giving up after timeout if non-zero.
+// A tx dropped before it could be fetched is reported as ethereum.NotFound,
+// even if the node returns no error.
+func fetchTx(ctx context.Context, client txFetcher, h common.Hash, timeout time.Duration) (*types.Transaction, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	tx, _, err := client.TransactionByHash(ctx, h)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, ethereum.NotFound
+	}
+	return tx, nil
+}
+
+// fetchRawTx is fetchTx keeping the JSON the node returned, it calls
+// eth_getTransactionByHash on rpccli directly.
+func fetchRawTx(ctx context.Context, rpccli *rpc.Client, h common.Hash, timeout time.Duration) (*types.Transaction, json.RawMessage, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var raw json.RawMessage
+	if err := rpccli.CallContext(ctx, &raw, "eth_getTransactionByHash", h); err != nil {
+		return nil, nil, err
+	}
+	tx, err := decodeRawTx(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, raw, nil
+}
+
+// decodeRawTx decodes a result of eth_getTransactionByHash, null being
+// ethereum.NotFound.
+func decodeRawTx(raw json.RawMessage) (*types.Transaction, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, ethereum.NotFound
+	}
+	tx := new(types.Transaction)
+	if err := json.Unmarshal(raw, tx); err != nil {
+		return nil, fmt.Errorf("decode tx: %w", err)
+	}
+	return tx, nil
+}
+
+// dispatch hands tx to handler if it involves a watched address. ctx bounds
+// the lookups made on the side, such as that of NonceGaps.
+func (m *Monitor) dispatch(ctx context.Context, tx *types.Transaction, handler func(*types.Transaction)) {
+	if tx.Protected() && tx.ChainId().Sign() == 0 {
+		slog.Warn("Replay protected tx carries chain ID 0, using the node's", "hash", tx.Hash(), "chainID", m.ChainID)
+	}
+
+	from, err := Sender(m.ChainID, tx)
+	if err != nil {
+		slog.Debug("Cannot recover sender, skipped", "hash", tx.Hash(), "err", err)
+		return
+	}
+
+	// We've got a tx
+	slog.Debug("Pending tx", "hash", tx.Hash(), "from", from)
+
+	if m.ExpectBlobs && m.blobs.observe(tx) {
+		slog.Warn("No blob tx among the pending txs so far, the node may not announce them", "seen", blobCheckAfter)
+	}
+
+	watched, ok := MatchTx(m.Match, m.Senders, m.Recipients, from, tx.To())
+	if !ok {
+		if watched, ok = MatchPrefixes(m.Match, m.Prefixes, from, tx.To()); !ok {
+			return
+		}
+	}
+
+	if !AndFilter(m.Filters...)(tx) {
+		slog.Debug("Tx filtered out", "hash", tx.Hash(), "watched", watched)
+		return
+	}
+
+	// we do something on it
+	var to any = tx.To()
+	if tx.To() != nil {
+		to = m.Labels.Name(*tx.To())
+	}
+	attrs := []any{"hash", tx.Hash(), "watched", m.Labels.Name(watched), "from", m.Labels.Name(from), "to", to, "value", tx.Value()}
+	if usd, ok := m.Price.ValueUSD(tx.Value()); ok {
+		attrs = append(attrs, "valueUsd", usd)
+	}
+	if tx.To() == nil {
+		attrs = append(attrs, "contract", crypto.CreateAddress(from, tx.Nonce()))
+	}
+	if hasFeeCap(tx) {
+		attrs = append(attrs, "tip", tx.GasTipCap())
+		if price := EffectiveGasPrice(tx, m.BaseFee()); price != nil {
+			attrs = append(attrs, "effectiveGasPrice", price)
+		}
+	}
+	if call := m.decodeCall(tx); call != nil {
+		attrs = append(attrs, "call", call.String())
+	} else if t, ok := DecodeTokenTransfer(tx.Data()); ok {
+		attrs = append(attrs, "erc20", t.Method, "amount", t.Amount, "recipient", t.To)
+	} else if len(tx.Data()) > 0 && m.DataLimit != 0 {
+		attrs = append(attrs, "input", FormatData(tx.Data(), m.DataLimit))
+	}
+	slog.Info(MatchedMsg, attrs...)
+	matches.Inc()
+	m.recordMatch(watched, tx)
+
+	// a watched sender reusing a nonce cancels or speeds up its earlier tx
+	if _, ok := m.Senders[from]; ok {
+		if prev, ok := m.sent.replaces(from, tx); ok {
+			replacements.Inc()
+			slog.Info("Replacement tx", "hash", tx.Hash(), "replaces", prev.hash, "from", from, "nonce", tx.Nonce(),
+				"oldGasPrice", prev.gasPrice, "newGasPrice", tx.GasPrice(), "oldTip", prev.tip, "newTip", tx.GasTipCap())
+		}
+		if m.NonceGaps {
+			m.wg.Add(1)
+			go func() {
+				defer m.wg.Done()
+				m.checkNonceGap(ctx, from, tx)
+			}()
+		}
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		handler(tx)
+	}()
+}
+
+// dispatchHash runs handler for h in its own goroutine.
+func (m *Monitor) dispatchHash(h common.Hash, handler func(common.Hash)) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		handler(h)
+	}()
+}
+
+// decodeCall decodes the input of tx with ABI, logging decode errors.
+func (m *Monitor) decodeCall(tx *types.Transaction) *Call {
+	if m.ABI == nil {
+		return nil
+	}
+	call, err := m.ABI.Decode(tx)
+	if err != nil {
+		slog.Warn("Decode input", "hash", tx.Hash(), "err", err)
+	}
+	return call
+}
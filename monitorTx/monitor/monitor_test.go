@@ -0,0 +1,200 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// fakeFetcher returns tx and err for every hash.
+type fakeFetcher struct {
+	tx  *types.Transaction
+	err error
+}
+
+func (f *fakeFetcher) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	return f.tx, true, f.err
+}
+
+func TestFetchTxNil(t *testing.T) {
+	tx, err := fetchTx(context.Background(), &fakeFetcher{}, common.Hash{1}, 0)
+	if tx != nil || !errors.Is(err, ethereum.NotFound) {
+		t.Errorf("got %v, %v, want nil, NotFound", tx, err)
+	}
+
+	want := types.NewTransaction(0, common.Address{}, nil, 0, nil, nil)
+	if tx, err := fetchTx(context.Background(), &fakeFetcher{tx: want}, want.Hash(), 0); tx != want || err != nil {
+		t.Errorf("got %v, %v, want tx", tx, err)
+	}
+}
+
+// slowFetcher answers after delay unless ctx is done first.
+type slowFetcher struct {
+	delay time.Duration
+}
+
+func (f *slowFetcher) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	select {
+	case <-time.After(f.delay):
+		return types.NewTransaction(0, common.Address{}, nil, 0, nil, nil), true, nil
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+}
+
+func TestFetchTxTimeout(t *testing.T) {
+	start := time.Now()
+	_, err := fetchTx(context.Background(), &slowFetcher{delay: time.Second}, common.Hash{1}, 10*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("fetch took %v despite the timeout", elapsed)
+	}
+
+	if _, err := fetchTx(context.Background(), &slowFetcher{}, common.Hash{1}, time.Second); err != nil {
+		t.Errorf("fast fetch: %v", err)
+	}
+}
+
+func TestDispatchBadSignature(t *testing.T) {
+	// a zero address target would match the bogus sender of a failed recovery
+	m := &Monitor{
+		Match:   MatchFrom,
+		Senders: AddressSet([]common.Address{{}}),
+	}
+
+	tx := types.NewTx(&types.LegacyTx{
+		Gas: 21000,
+		V:   big.NewInt(27),
+		R:   big.NewInt(0),
+		S:   big.NewInt(0),
+	})
+	if _, err := Sender(nil, tx); err == nil {
+		t.Fatal("sender of unsigned tx recovered")
+	}
+
+	called := false
+	m.dispatch(context.Background(), tx, func(*types.Transaction) { called = true })
+	m.wg.Wait()
+
+	if called {
+		t.Error("handler called for a tx without valid signature")
+	}
+}
+
+func TestSenderTxTypes(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	want := crypto.PubkeyToAddress(key.PublicKey)
+	chainID := big.NewInt(5)
+	to := common.Address{1}
+
+	txs := map[string]types.TxData{
+		"legacy": &types.LegacyTx{Nonce: 1, To: &to, Gas: 21000, GasPrice: big.NewInt(1)},
+		"access list": &types.AccessListTx{ChainID: chainID, Nonce: 1, To: &to, Gas: 21000, GasPrice: big.NewInt(1),
+			AccessList: types.AccessList{{Address: to}}},
+		"dynamic fee": &types.DynamicFeeTx{ChainID: chainID, Nonce: 1, To: &to, Gas: 21000,
+			GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2)},
+	}
+
+	for name, data := range txs {
+		tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if from, err := Sender(chainID, tx); err != nil || from != want {
+			t.Errorf("%s: got %x, %v, want %x", name, from, err, want)
+		}
+	}
+
+	// a pre EIP-155 tx carries no chain ID at all
+	homestead, _ := types.SignNewTx(key, types.HomesteadSigner{}, txs["legacy"])
+	if from, err := Sender(chainID, homestead); err != nil || from != want {
+		t.Errorf("unprotected: got %x, %v, want %x", from, err, want)
+	}
+}
+
+func TestSenderZeroChainID(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	to := common.Address{1}
+
+	// an EIP-155 signature for chain 0 would have V 35 or 36
+	signed, err := types.SignNewTx(key, types.HomesteadSigner{},
+		&types.LegacyTx{Nonce: 1, To: &to, Gas: 21000, GasPrice: big.NewInt(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, r, sig := signed.RawSignatureValues()
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, To: &to, Gas: 21000, GasPrice: big.NewInt(1),
+		V: new(big.Int).Add(v, big.NewInt(8)), R: r, S: sig})
+	if !tx.Protected() || tx.ChainId().Sign() != 0 {
+		t.Fatalf("crafted tx: protected %v, chain ID %v", tx.Protected(), tx.ChainId())
+	}
+
+	if _, err := Sender(nil, tx); !errors.Is(err, ErrZeroChainID) {
+		t.Errorf("no chain ID: got %v, want ErrZeroChainID", err)
+	}
+
+	// the node's chain ID is used instead, which the signature doesn't match
+	if from, err := Sender(big.NewInt(1), tx); err == nil {
+		t.Errorf("chain 1: recovered %x", from)
+	}
+
+	m := &Monitor{
+		Senders: AddressSet([]common.Address{crypto.PubkeyToAddress(key.PublicKey)}),
+		Match:   MatchFrom,
+		ChainID: big.NewInt(1),
+	}
+	called := false
+	m.dispatch(context.Background(), tx, func(*types.Transaction) { called = true })
+	m.wg.Wait()
+	if called {
+		t.Error("handler called for a tx signed for chain 0")
+	}
+}
+
+func TestHeartbeatStops(t *testing.T) {
+	m := &Monitor{Heartbeat: time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		m.heartbeat(ctx)
+		close(done)
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("heartbeat still running after cancel")
+	}
+}
+
+func TestJitter(t *testing.T) {
+	d := 100 * time.Millisecond
+	distinct := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		j := jitter(d)
+		if j <= 0 || j > d {
+			t.Fatalf("jitter %v out of (0, %v]", j, d)
+		}
+		distinct[j] = true
+	}
+	if len(distinct) < 2 {
+		t.Error("jitter is not random")
+	}
+	if j := jitter(0); j != 0 {
+		t.Errorf("jitter(0) = %v", j)
+	}
+}
@@ -0,0 +1,57 @@
+package monitor
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// nonceTracker hands out consecutive nonces to responses sent in quick
+// succession, which would all get the same pending nonce from the node.
+type nonceTracker struct {
+	mu     sync.Mutex
+	next   uint64
+	synced bool
+}
+
+// reserve locks the tracker until release and returns the nonce to use,
+// read from the node's pending state on first use or after a resync.
+func (n *nonceTracker) reserve(ctx context.Context, client TxClient, from common.Address) (uint64, error) {
+	n.mu.Lock()
+	if !n.synced {
+		next, err := client.PendingNonceAt(ctx, from)
+		if err != nil {
+			n.mu.Unlock()
+			return 0, err
+		}
+		n.next, n.synced = next, true
+	}
+	return n.next, nil
+}
+
+// release unlocks the tracker, moving to the next nonce if the reserved one
+// was sent. A nonce error from the node resyncs on the next reserve.
+func (n *nonceTracker) release(sent bool, err error) {
+	defer n.mu.Unlock()
+
+	switch {
+	case sent:
+		n.next++
+	case isNonceError(err):
+		slog.Warn("Nonce rejected, resyncing", "nonce", n.next, "err", err)
+		n.synced = false
+	}
+}
+
+// isNonceError reports whether err is the node rejecting a tx nonce.
+func isNonceError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "nonce too low") || strings.Contains(msg, "nonce too high") ||
+		strings.Contains(msg, "replacement transaction underpriced")
+}
@@ -0,0 +1,76 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestNonceBurst(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	client := newFakeClient()
+	client.nonce = 3
+	r := &Responder{Key: key, To: &responseTo}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := r.Process(context.Background(), valueTx(0, nil), client); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	used := make(map[uint64]bool)
+	for _, tx := range client.sent {
+		used[tx.Nonce()] = true
+	}
+	for n := uint64(3); n < 8; n++ {
+		if !used[n] {
+			t.Errorf("nonce %d not used, sent %v", n, used)
+		}
+	}
+}
+
+func TestNonceResync(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	client := newFakeClient()
+	r := &Responder{Key: key, To: &responseTo}
+
+	if err := r.Process(context.Background(), valueTx(0, nil), client); err != nil {
+		t.Fatal(err)
+	}
+
+	// a failure unrelated to the nonce keeps it
+	client.sendErr = errors.New("insufficient funds for gas * price + value")
+	if err := r.Process(context.Background(), valueTx(0, nil), client); err == nil {
+		t.Fatal("send error not returned")
+	}
+	client.sendErr = nil
+	if err := r.Process(context.Background(), valueTx(0, nil), client); err != nil {
+		t.Fatal(err)
+	}
+	if got := client.sent[1].Nonce(); got != 1 {
+		t.Errorf("after a failed send: got nonce %d, want 1", got)
+	}
+
+	// the account was used elsewhere, the node rejects the local nonce
+	client.nonce = 10
+	client.sendErr = errors.New("nonce too low: next nonce 10, tx nonce 2")
+	if err := r.Process(context.Background(), valueTx(0, nil), client); err == nil {
+		t.Fatal("send error not returned")
+	}
+	client.sendErr = nil
+	if err := r.Process(context.Background(), valueTx(0, nil), client); err != nil {
+		t.Fatal(err)
+	}
+	if got := client.sent[2].Nonce(); got != 10 {
+		t.Errorf("after resync: got nonce %d, want 10", got)
+	}
+}
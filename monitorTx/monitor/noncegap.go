@@ -0,0 +1,90 @@
+package monitor
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// nonceGapTTL is how long the pending nonce of a sender is taken from the
+// cache, about a block, before it is looked up again.
+const nonceGapTTL = 12 * time.Second
+
+type cachedNonce struct {
+	next uint64
+	at   time.Time
+}
+
+// nonceGaps caches the next nonce expected of every watched sender, from
+// its pending nonce and the txs matched since. It is safe for concurrent
+// use.
+type nonceGaps struct {
+	mu    sync.Mutex
+	nonce map[common.Address]cachedNonce
+}
+
+// check returns the nonce expected of the next tx of from, fetching its
+// pending nonce if the cached one is stale, and whether nonce skips past
+// it. A tx at or below the expected nonce moves it on.
+func (g *nonceGaps) check(ctx context.Context, client TxClient, from common.Address, nonce uint64) (uint64, bool, error) {
+	g.mu.Lock()
+	c, ok := g.nonce[from]
+	g.mu.Unlock()
+
+	// the lock is not held over the fetch, a concurrent check of from may
+	// store its entry meanwhile
+	var fetched *cachedNonce
+	if start := time.Now(); !ok || time.Since(c.at) > nonceGapTTL {
+		next, err := client.PendingNonceAt(ctx, from)
+		if err != nil {
+			return 0, false, err
+		}
+		fetched = &cachedNonce{next: next, at: start}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.nonce == nil {
+		g.nonce = make(map[common.Address]cachedNonce)
+	}
+	c, ok = g.nonce[from]
+	if fetched != nil {
+		if ok && !c.at.Before(fetched.at) {
+			// stored since the fetch started, keep what it advanced
+			c.next = max(c.next, fetched.next)
+		} else {
+			c = cachedNonce{next: fetched.next, at: time.Now()}
+		}
+	}
+
+	expected := c.next
+	if nonce <= c.next {
+		c.next = max(c.next, nonce+1)
+	}
+	g.nonce[from] = c
+	return expected, nonce > expected, nil
+}
+
+// checkNonceGap warns when tx of the watched sender from has a nonce past
+// the next one due, which holds it back until the earlier txs are mined.
+func (m *Monitor) checkNonceGap(ctx context.Context, from common.Address, tx *types.Transaction) {
+	if m.FetchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.FetchTimeout)
+		defer cancel()
+	}
+
+	expected, gap, err := m.nonceGaps.check(ctx, m.Client(), from, tx.Nonce())
+	if err != nil {
+		slog.Debug("Pending nonce lookup failed", "from", from, "err", err)
+		return
+	}
+	if gap {
+		slog.Warn("Nonce gap, an earlier tx may be stuck", "hash", tx.Hash(), "from", m.Labels.Name(from),
+			"nonce", tx.Nonce(), "expected", expected, "missing", tx.Nonce()-expected)
+	}
+}
@@ -0,0 +1,98 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestNonceGaps(t *testing.T) {
+	client := newFakeClient()
+	client.nonce = 5
+	from := common.Address{1}
+
+	var g nonceGaps
+	steps := []struct {
+		nonce    uint64
+		expected uint64
+		gap      bool
+	}{
+		{5, 5, false},
+		{6, 6, false},
+		{8, 7, true},
+		{8, 7, true},
+		{7, 7, false},
+		{8, 8, false},
+		// a replacement of an earlier nonce
+		{6, 9, false},
+	}
+	for i, s := range steps {
+		expected, gap, err := g.check(context.Background(), client, from, s.nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected != s.expected || gap != s.gap {
+			t.Errorf("step %d, nonce %d: got expected %d gap %v, want %d %v", i, s.nonce, expected, gap, s.expected, s.gap)
+		}
+		// the cached nonce is used, not looked up on every match
+		client.nonce = 0
+	}
+}
+
+// heldNonceClient answers PendingNonceAt with nonce once the call's
+// channel sent on entered is closed.
+type heldNonceClient struct {
+	*fakeClient
+	nonce   uint64
+	entered chan chan struct{}
+}
+
+func (c *heldNonceClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	release := make(chan struct{})
+	c.entered <- release
+	<-release
+	return c.nonce, nil
+}
+
+func TestNonceGapsConcurrentFetch(t *testing.T) {
+	client := &heldNonceClient{fakeClient: newFakeClient(), nonce: 5, entered: make(chan chan struct{})}
+	from := common.Address{1}
+	var g nonceGaps
+
+	type result struct {
+		expected uint64
+		gap      bool
+	}
+	check := func(nonce uint64) <-chan result {
+		out := make(chan result, 1)
+		go func() {
+			expected, gap, err := g.check(context.Background(), client, from, nonce)
+			if err != nil {
+				t.Error(err)
+			}
+			out <- result{expected, gap}
+		}()
+		return out
+	}
+
+	// both txs of from find no cached nonce and fetch it, the second
+	// fetch lands after the first stored its advance
+	first := check(5)
+	releaseFirst := <-client.entered
+	second := check(6)
+	releaseSecond := <-client.entered
+
+	close(releaseFirst)
+	if r := <-first; r.expected != 5 || r.gap {
+		t.Errorf("nonce 5: got expected %d gap %v, want 5 false", r.expected, r.gap)
+	}
+	close(releaseSecond)
+	if r := <-second; r.expected != 6 || r.gap {
+		t.Errorf("nonce 6: got expected %d gap %v, want 6 false", r.expected, r.gap)
+	}
+
+	if expected, gap, _ := g.check(context.Background(), client, from, 7); expected != 7 || gap {
+		t.Errorf("nonce 7: got expected %d gap %v, want 7 false", expected, gap)
+	}
+}
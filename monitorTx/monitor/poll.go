@@ -0,0 +1,150 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultPollInterval is the delay between polls over HTTP.
+const DefaultPollInterval = 5 * time.Second
+
+// isHTTP reports whether url needs polling as it cannot carry subscriptions.
+func isHTTP(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
+
+// methodNotFoundCode is the JSON-RPC error code of a method the node
+// doesn't expose.
+const methodNotFoundCode = -32601
+
+// isMethodNotFound reports whether err is a node rejecting a call to a
+// method it doesn't expose.
+func isMethodNotFound(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) && rpcErr.ErrorCode() == methodNotFoundCode
+}
+
+// txpoolContent mirrors the result of txpool_content: status, sender, nonce.
+type txpoolContent map[string]map[string]map[string]*types.Transaction
+
+// poll feeds txs with new transactions every PollInterval until ctx is done.
+// It reads the node's tx pool with txpool_content and, if the node doesn't
+// expose it, falls back to the transactions of every new block. Any other
+// txpool_content error is retried on the next tick.
+func (m *Monitor) poll(ctx context.Context, txs chan<- fetchedTx) {
+	interval := m.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	usePool := true
+	seen := make(map[common.Hash]struct{})
+	var next *big.Int
+
+	for {
+		var found []*types.Transaction
+		var err error
+
+		if usePool {
+			if found, err = m.pollPool(ctx, seen); err != nil && ctx.Err() == nil {
+				if isMethodNotFound(err) {
+					slog.Warn("txpool_content unavailable, polling new blocks instead", "err", err)
+					usePool = false
+				} else {
+					slog.Warn("Poll txpool_content failed", "err", err)
+					observeRPCError(CallFetch, err)
+				}
+			}
+		}
+		if !usePool {
+			if found, next, err = m.pollBlocks(ctx, next); err != nil && ctx.Err() == nil {
+				slog.Warn("Poll failed", "err", err)
+				observeRPCError(CallFetch, err)
+			}
+		}
+
+		m.received.Add(uint64(len(found)))
+		for _, tx := range found {
+			select {
+			case txs <- fetchedTx{tx: tx}:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollPool returns the pending txs not in seen, then replaces seen with the
+// current pool so it never outgrows it.
+func (m *Monitor) pollPool(ctx context.Context, seen map[common.Hash]struct{}) ([]*types.Transaction, error) {
+	var content txpoolContent
+	if err := m.rpcClient().CallContext(ctx, &content, "txpool_content"); err != nil {
+		return nil, err
+	}
+
+	var found []*types.Transaction
+	current := make(map[common.Hash]struct{})
+
+	for _, senders := range content {
+		for _, nonces := range senders {
+			for _, tx := range nonces {
+				if tx == nil {
+					continue
+				}
+				current[tx.Hash()] = struct{}{}
+				if _, ok := seen[tx.Hash()]; !ok {
+					found = append(found, tx)
+				}
+			}
+		}
+	}
+
+	for h := range seen {
+		delete(seen, h)
+	}
+	for h := range current {
+		seen[h] = struct{}{}
+	}
+	return found, nil
+}
+
+// pollBlocks returns the txs of the blocks from next up to the head, and the
+// number to continue from. A nil next starts at the head.
+func (m *Monitor) pollBlocks(ctx context.Context, next *big.Int) ([]*types.Transaction, *big.Int, error) {
+	client := m.Client()
+
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return nil, next, err
+	}
+
+	if next == nil {
+		next = new(big.Int).SetUint64(head)
+	}
+
+	var found []*types.Transaction
+	for ; next.Uint64() <= head; next = new(big.Int).Add(next, big.NewInt(1)) {
+		block, err := client.BlockByNumber(ctx, next)
+		if err != nil {
+			return found, next, err
+		}
+		found = append(found, block.Transactions()...)
+	}
+	return found, next, nil
+}
@@ -0,0 +1,65 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// txpoolNode serves txpool_content, failing the first fails calls.
+type txpoolNode struct {
+	mu    sync.Mutex
+	fails int
+	calls int
+	tx    *types.Transaction
+}
+
+func (n *txpoolNode) Content() (txpoolContent, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.calls++
+	if n.calls <= n.fails {
+		return nil, errors.New("upstream timeout")
+	}
+	return txpoolContent{"pending": {"0x01": {"0": n.tx}}}, nil
+}
+
+func TestPollPoolRecovers(t *testing.T) {
+	tx := types.NewTx(&types.LegacyTx{Nonce: 0, Gas: 21000, GasPrice: big.NewInt(1), To: &common.Address{1}})
+	node := &txpoolNode{fails: 2, tx: tx}
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("txpool", node); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Monitor{PollInterval: time.Millisecond}
+	m.setClient(rpc.DialInProc(server))
+	defer m.Close()
+
+	if err := m.rpcClient().Call(nil, "txpool_inspect"); !isMethodNotFound(err) {
+		t.Errorf("missing method: %v, want method not found", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	txs := make(chan fetchedTx)
+	go m.poll(ctx, txs)
+
+	select {
+	case f := <-txs:
+		if f.tx.Hash() != tx.Hash() {
+			t.Errorf("polled %s, want %s", f.tx.Hash(), tx.Hash())
+		}
+	case <-ctx.Done():
+		t.Fatal("pool polling didn't recover from a transient error")
+	}
+
+}
@@ -0,0 +1,87 @@
+package monitor
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AddressPrefix matches the addresses whose hex form starts with a given
+// number of nibbles, such as a family of vanity addresses.
+type AddressPrefix struct {
+	// whole holds the leading full bytes, half the nibble after them
+	whole []byte
+	half  byte
+	odd   bool
+}
+
+// ParseAddressPrefix parses a prefix of 1 to 40 hex digits, with or
+// without 0x, in any case.
+func ParseAddressPrefix(s string) (AddressPrefix, error) {
+	digits := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X"))
+	if len(digits) == 0 || len(digits) > 2*common.AddressLength {
+		return AddressPrefix{}, fmt.Errorf("invalid address prefix %q, want 1 to 40 hex digits", s)
+	}
+
+	var p AddressPrefix
+	if len(digits)%2 == 1 {
+		p.odd = true
+		last := digits[len(digits)-1:]
+		digits = digits[:len(digits)-1]
+		b, err := hex.DecodeString("0" + last)
+		if err != nil {
+			return AddressPrefix{}, fmt.Errorf("invalid address prefix %q", s)
+		}
+		p.half = b[0]
+	}
+	var err error
+	if p.whole, err = hex.DecodeString(digits); err != nil {
+		return AddressPrefix{}, fmt.Errorf("invalid address prefix %q", s)
+	}
+	return p, nil
+}
+
+// ParseAddressPrefixes parses every prefix of list.
+func ParseAddressPrefixes(list []string) ([]AddressPrefix, error) {
+	out := make([]AddressPrefix, 0, len(list))
+	for _, s := range list {
+		p, err := ParseAddressPrefix(s)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// Match reports whether addr starts with p.
+func (p AddressPrefix) Match(addr common.Address) bool {
+	n := len(p.whole)
+	if string(addr[:n]) != string(p.whole) {
+		return false
+	}
+	return !p.odd || addr[n]>>4 == p.half
+}
+
+func (p AddressPrefix) String() string {
+	s := "0x" + hex.EncodeToString(p.whole)
+	if p.odd {
+		s += fmt.Sprintf("%x", p.half)
+	}
+	return s
+}
+
+// MatchPrefixes is MatchTx for addresses starting with one of prefixes.
+func MatchPrefixes(mode string, prefixes []AddressPrefix, from common.Address, to *common.Address) (common.Address, bool) {
+	for _, p := range prefixes {
+		if (mode == MatchFrom || mode == MatchEither) && p.Match(from) {
+			return from, true
+		}
+		if (mode == MatchTo || mode == MatchEither) && to != nil && p.Match(*to) {
+			return *to, true
+		}
+	}
+	return common.Address{}, false
+}
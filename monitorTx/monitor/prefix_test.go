@@ -0,0 +1,73 @@
+package monitor
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestAddressPrefix(t *testing.T) {
+	addr := common.HexToAddress("0xdEAD5Df5FeF651EF0C59cD175c73ca1415f53eA0")
+
+	tests := []struct {
+		prefix string
+		want   bool
+	}{
+		{"0xd", true},
+		{"0xdea", true},
+		{"DEAD5", true},
+		{"0xdead", true},
+		{"0xdeae", false},
+		{"0xdeb", false},
+		{"0xe", false},
+		{"0xdead5df5fef651ef0c59cd175c73ca1415f53ea", true},
+		{"0xdead5df5fef651ef0c59cd175c73ca1415f53ea0", true},
+		{"0xdead5df5fef651ef0c59cd175c73ca1415f53ea1", false},
+	}
+	for _, tt := range tests {
+		p, err := ParseAddressPrefix(tt.prefix)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.prefix, err)
+		}
+		if got := p.Match(addr); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.prefix, got, tt.want)
+		}
+	}
+
+	if p, _ := ParseAddressPrefix("0xDEa"); p.String() != "0xdea" {
+		t.Errorf("String: got %s", p)
+	}
+	for _, bad := range []string{"", "0x", "0xdeg", "0xg", "0xdead5df5fef651ef0c59cd175c73ca1415f53ea01"} {
+		if _, err := ParseAddressPrefix(bad); err == nil {
+			t.Errorf("%q accepted", bad)
+		}
+	}
+}
+
+func TestMatchPrefixes(t *testing.T) {
+	prefixes, err := ParseAddressPrefixes([]string{"0xaaa"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vanity := common.HexToAddress("0xaaa0000000000000000000000000000000000001")
+	other := common.Address{1}
+
+	tests := []struct {
+		mode     string
+		from, to common.Address
+		want     bool
+	}{
+		{MatchFrom, vanity, other, true},
+		{MatchFrom, other, vanity, false},
+		{MatchTo, other, vanity, true},
+		{MatchEither, other, vanity, true},
+		{MatchEither, other, other, false},
+	}
+	for _, tt := range tests {
+		to := tt.to
+		watched, ok := MatchPrefixes(tt.mode, prefixes, tt.from, &to)
+		if ok != tt.want || (ok && watched != vanity) {
+			t.Errorf("%s %x -> %x: got %x %v", tt.mode, tt.from, tt.to, watched, ok)
+		}
+	}
+}
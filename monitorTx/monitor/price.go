@@ -0,0 +1,265 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	// CoingeckoURL is the ETH/USD price of the coingecko price source.
+	CoingeckoURL = "https://api.coingecko.com/api/v3/simple/price?ids=ethereum&vs_currencies=usd"
+
+	// DefaultPriceInterval is how often a PriceFeed refreshes the price.
+	DefaultPriceInterval = time.Minute
+
+	// DefaultPriceTimeout bounds a single price lookup.
+	DefaultPriceTimeout = 10 * time.Second
+)
+
+// ChainlinkETHUSD is the ETH/USD feed of Chainlink on mainnet.
+var ChainlinkETHUSD = common.HexToAddress("0x5f4eC3Df9cbd43714FE2740f5E3616155c5b8419")
+
+// PriceSource looks up the price of one ETH in USD.
+type PriceSource interface {
+	USDPrice(ctx context.Context) (float64, error)
+}
+
+// ParsePriceSource parses coingecko, an http(s) url answering JSON such as
+// {"usd": 1234.5}, or chainlink with an optional :feed address, the latter
+// read through client, which is only called on a lookup.
+func ParsePriceSource(s string, client func() TxClient) (PriceSource, error) {
+	switch {
+	case s == "coingecko":
+		return NewHTTPPrice(CoingeckoURL, DefaultPriceTimeout), nil
+	case strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://"):
+		return NewHTTPPrice(s, DefaultPriceTimeout), nil
+	case s == "chainlink":
+		return &ChainlinkPrice{Feed: ChainlinkETHUSD, Client: client}, nil
+	case strings.HasPrefix(s, "chainlink:"):
+		feed := strings.TrimPrefix(s, "chainlink:")
+		if !common.IsHexAddress(feed) {
+			return nil, fmt.Errorf("price source %q: invalid feed address", s)
+		}
+		return &ChainlinkPrice{Feed: common.HexToAddress(feed), Client: client}, nil
+	}
+	return nil, fmt.Errorf("unknown price source %q, want coingecko, an http(s) url or chainlink[:feed]", s)
+}
+
+// HTTPPrice takes the price from the JSON answer of a URL, see parsePrice.
+type HTTPPrice struct {
+	URL string
+
+	client *http.Client
+}
+
+func NewHTTPPrice(url string, timeout time.Duration) *HTTPPrice {
+	return &HTTPPrice{URL: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (p *HTTPPrice) USDPrice(ctx context.Context) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return 0, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return 0, err
+	}
+	return parsePrice(body)
+}
+
+// parsePrice reads the price of the coingecko answer
+// {"ethereum": {"usd": 1234.5}}, or of a flat {"usd": ...} or
+// {"price": ...}.
+func parsePrice(body []byte) (float64, error) {
+	var answer struct {
+		Ethereum *struct {
+			USD *float64 `json:"usd"`
+		} `json:"ethereum"`
+		USD   *float64 `json:"usd"`
+		Price *float64 `json:"price"`
+	}
+	if err := json.Unmarshal(body, &answer); err != nil {
+		return 0, fmt.Errorf("invalid price answer: %v", err)
+	}
+
+	var price *float64
+	switch {
+	case answer.Ethereum != nil && answer.Ethereum.USD != nil:
+		price = answer.Ethereum.USD
+	case answer.USD != nil:
+		price = answer.USD
+	case answer.Price != nil:
+		price = answer.Price
+	}
+	if price == nil || *price <= 0 {
+		return 0, errors.New("no usd price in answer")
+	}
+	return *price, nil
+}
+
+// Selectors of the Chainlink aggregator calls.
+var (
+	selectorDecimals        = crypto.Keccak256([]byte("decimals()"))[:4]
+	selectorLatestRoundData = crypto.Keccak256([]byte("latestRoundData()"))[:4]
+)
+
+// ChainlinkPrice reads the latest answer of a Chainlink price feed.
+type ChainlinkPrice struct {
+	Feed   common.Address
+	Client func() TxClient
+
+	// decimals of the feed, read on the first successful lookup
+	mu       sync.Mutex
+	decimals int
+	known    bool
+}
+
+func (p *ChainlinkPrice) USDPrice(ctx context.Context) (float64, error) {
+	if p.Client == nil {
+		return 0, errors.New("chainlink price source without a node")
+	}
+	client := p.Client()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.known {
+		out, err := p.call(ctx, client, selectorDecimals, 1)
+		if err != nil {
+			return 0, fmt.Errorf("chainlink decimals: %w", err)
+		}
+		p.decimals, p.known = int(new(big.Int).SetBytes(out[:32]).Int64()), true
+	}
+
+	// roundId, answer, startedAt, updatedAt, answeredInRound
+	out, err := p.call(ctx, client, selectorLatestRoundData, 5)
+	if err != nil {
+		return 0, fmt.Errorf("chainlink latestRoundData: %w", err)
+	}
+	answer := new(big.Int).SetBytes(out[32:64])
+	if out[32]&0x80 != 0 || answer.Sign() == 0 {
+		return 0, errors.New("chainlink answer not positive")
+	}
+	price, _ := new(big.Rat).SetFrac(answer, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(p.decimals)), nil)).Float64()
+	return price, nil
+}
+
+// call calls the feed, expecting at least words words of result.
+func (p *ChainlinkPrice) call(ctx context.Context, client TxClient, selector []byte, words int) ([]byte, error) {
+	out, err := client.CallContract(ctx, ethereum.CallMsg{To: &p.Feed, Data: selector}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) < 32*words {
+		return nil, fmt.Errorf("short result of %d bytes from %s", len(out), p.Feed)
+	}
+	return out, nil
+}
+
+// PriceFeed keeps the ETH price of a source up to date. A price that
+// could not be refreshed for three intervals is dropped rather than shown
+// stale. It is safe for concurrent use.
+type PriceFeed struct {
+	Source   PriceSource
+	Interval time.Duration
+
+	mu  sync.Mutex
+	usd float64
+	at  time.Time
+}
+
+func NewPriceFeed(src PriceSource, interval time.Duration) *PriceFeed {
+	if interval <= 0 {
+		interval = DefaultPriceInterval
+	}
+	return &PriceFeed{Source: src, Interval: interval}
+}
+
+// Run refreshes the price every Interval until ctx is done.
+func (f *PriceFeed) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.Interval)
+	defer ticker.Stop()
+	for {
+		f.refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (f *PriceFeed) refresh(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultPriceTimeout)
+	defer cancel()
+
+	usd, err := f.Source.USDPrice(ctx)
+	if err != nil {
+		if ctx.Err() == nil || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			slog.Warn("Price lookup failed", "err", err)
+		}
+		return
+	}
+	slog.Debug("Price updated", "usd", usd)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.usd, f.at = usd, time.Now()
+}
+
+// USD returns the current price of one ETH, false if there is none.
+func (f *PriceFeed) USD() (float64, bool) {
+	if f == nil {
+		return 0, false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.at.IsZero() || time.Since(f.at) > 3*f.Interval {
+		return 0, false
+	}
+	return f.usd, true
+}
+
+// ValueUSD returns the worth of wei at the current price, rounded to
+// cents, and false if there is no price.
+func (f *PriceFeed) ValueUSD(wei *big.Int) (float64, bool) {
+	usd, ok := f.USD()
+	if !ok || wei == nil {
+		return 0, false
+	}
+	return USDValue(wei, usd), true
+}
+
+// USDValue returns the worth of wei at usd per ETH, rounded to cents.
+func USDValue(wei *big.Int, usd float64) float64 {
+	cents := new(big.Float).SetInt(wei)
+	cents.Mul(cents, big.NewFloat(usd*100))
+	cents.Quo(cents, new(big.Float).SetInt64(params.Ether))
+	rounded, _ := cents.Add(cents, big.NewFloat(0.5)).Int(nil)
+	v, _ := new(big.Rat).SetFrac(rounded, big.NewInt(100)).Float64()
+	return v
+}
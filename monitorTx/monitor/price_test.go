@@ -0,0 +1,136 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		body string
+		want float64
+		ok   bool
+	}{
+		{`{"ethereum":{"usd":2543.21}}`, 2543.21, true},
+		{`{"usd":1800}`, 1800, true},
+		{`{"symbol":"ETHUSDT","price":"x"}`, 0, false},
+		{`{"price":3000.5}`, 3000.5, true},
+		{`{"ethereum":{}}`, 0, false},
+		{`{"usd":-1}`, 0, false},
+		{`[]`, 0, false},
+	}
+	for _, tt := range tests {
+		got, err := parsePrice([]byte(tt.body))
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("parsePrice(%s) = %v, %v, want %v", tt.body, got, err, tt.want)
+		}
+	}
+}
+
+func TestHTTPPrice(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ethereum":{"usd":2000}}`)
+	}))
+	defer ts.Close()
+
+	src, err := ParsePriceSource(ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := src.USDPrice(context.Background()); err != nil || got != 2000 {
+		t.Errorf("price %v, %v, want 2000", got, err)
+	}
+}
+
+// word encodes v as a 32 byte ABI word.
+func word(v int64) []byte {
+	return common.LeftPadBytes(big.NewInt(v).Bytes(), 32)
+}
+
+func TestChainlinkPrice(t *testing.T) {
+	client := newFakeClient()
+	client.calls = map[[4]byte][]byte{
+		[4]byte(selectorDecimals):        word(8),
+		[4]byte(selectorLatestRoundData): append(append(append(word(1), word(254321000000)...), word(0)...), append(word(0), word(1)...)...),
+	}
+
+	src, err := ParsePriceSource("chainlink", func() TxClient { return client })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := src.USDPrice(context.Background()); err != nil || got != 2543.21 {
+		t.Errorf("price %v, %v, want 2543.21", got, err)
+	}
+
+	client.callErr = errors.New("execution reverted")
+	if _, err := src.USDPrice(context.Background()); err == nil {
+		t.Error("failed call gave a price")
+	}
+}
+
+func TestParsePriceSource(t *testing.T) {
+	for _, s := range []string{"coingecko", "https://example.org/price", "chainlink", "chainlink:0x5f4eC3Df9cbd43714FE2740f5E3616155c5b8419"} {
+		if _, err := ParsePriceSource(s, nil); err != nil {
+			t.Errorf("%s: %v", s, err)
+		}
+	}
+	for _, s := range []string{"", "binance", "chainlink:0x12"} {
+		if _, err := ParsePriceSource(s, nil); err == nil {
+			t.Errorf("%q accepted", s)
+		}
+	}
+}
+
+func TestUSDValue(t *testing.T) {
+	wei, _ := new(big.Int).SetString("1500000000000000000", 10)
+	if got := USDValue(wei, 2000.123); got != 3000.18 {
+		t.Errorf("1.5 ETH worth %v, want 3000.18", got)
+	}
+	if got := USDValue(big.NewInt(0), 2000); got != 0 {
+		t.Errorf("0 wei worth %v", got)
+	}
+}
+
+// priceFunc adapts a func to a PriceSource.
+type priceFunc func() (float64, error)
+
+func (f priceFunc) USDPrice(context.Context) (float64, error) { return f() }
+
+func TestPriceFeed(t *testing.T) {
+	var err error
+	f := NewPriceFeed(priceFunc(func() (float64, error) { return 2000, err }), time.Minute)
+
+	if _, ok := f.USD(); ok {
+		t.Error("price before the first lookup")
+	}
+	err = errors.New("unavailable")
+	f.refresh(context.Background())
+	if _, ok := f.USD(); ok {
+		t.Error("price after a failed lookup")
+	}
+
+	err = nil
+	f.refresh(context.Background())
+	if usd, ok := f.ValueUSD(big.NewInt(5e17)); !ok || usd != 1000 {
+		t.Errorf("0.5 ETH worth %v, %v, want 1000", usd, ok)
+	}
+
+	// a price not refreshed for long is dropped
+	f.at = time.Now().Add(-4 * time.Minute)
+	if _, ok := f.USD(); ok {
+		t.Error("stale price kept")
+	}
+
+	var none *PriceFeed
+	if _, ok := none.ValueUSD(big.NewInt(1)); ok {
+		t.Error("price without a feed")
+	}
+}
@@ -0,0 +1,290 @@
+package monitor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	ErrNoKey           = errors.New("no signing key configured")
+	ErrNoRecipient     = errors.New("no response recipient configured")
+	ErrGasPriceTooHigh = errors.New("gas price above the configured maximum")
+	ErrNoFunds         = errors.New("balance below the cost of the response")
+)
+
+// Response transaction types.
+const (
+	TxLegacy  = "legacy"
+	TxDynamic = "dynamic"
+)
+
+// Responder acts on matched transactions with a tx of its own.
+type Responder struct {
+	Key *ecdsa.PrivateKey
+
+	// To receives the response, Value in wei and Data as its input, such
+	// as a contract call.
+	To    *common.Address
+	Value *big.Int
+	Data  []byte
+
+	// TxType is TxLegacy or TxDynamic, defaulting to legacy.
+	TxType string
+
+	// ChainID signs the response, it is fetched from the node when nil.
+	ChainID *big.Int
+
+	// DryRun logs the signed tx instead of broadcasting it.
+	DryRun bool
+
+	// PrintRaw logs every signed tx, broadcast or not.
+	PrintRaw bool
+
+	// Simulate runs the response as an eth_call first and skips sending it
+	// if the call reverts.
+	Simulate bool
+
+	// MaxGasPrice, if set, caps the price paid per gas in wei. Process
+	// skips sending when the node suggests more.
+	MaxGasPrice *big.Int
+
+	// GasPadding is the percentage added on top of the estimated gas.
+	GasPadding int
+
+	// SendRetries is how many times a send failing transiently, such as on
+	// a timeout or rate limit, is retried, waiting SendBackoff and then
+	// twice as long every time.
+	SendRetries int
+	SendBackoff time.Duration
+
+	// ReplaceAfter, if set, is how long a sent response may stay unmined
+	// before it is resent at a higher gas price, at most MaxBumps times.
+	ReplaceAfter time.Duration
+	MaxBumps     int
+
+	nonces nonceTracker
+}
+
+// Process is an example handler for a matched transaction. Cancelling ctx
+// aborts its calls to the node.
+func (r *Responder) Process(ctx context.Context, t *types.Transaction, client TxClient) (err error) {
+	defer func() { observeProcess(err) }()
+
+	// We can do something evil if this specific tx sent by your designated address
+	// for example, send a tx to inform someone
+
+	if r.Key == nil {
+		return ErrNoKey
+	}
+	if r.To == nil {
+		return ErrNoRecipient
+	}
+	key := r.Key
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	nonce, err := r.nonces.reserve(ctx, client, from)
+	if err != nil {
+		return err
+	}
+	tx, signer, err := r.respond(ctx, client, key, from, nonce)
+	r.nonces.release(tx != nil, err)
+	if err != nil || tx == nil {
+		return err
+	}
+
+	if r.ReplaceAfter > 0 {
+		return r.confirm(ctx, client, signer, key, tx)
+	}
+	return nil
+}
+
+// Handle makes r a Handler, it processes the tx of m on its client.
+func (r *Responder) Handle(ctx context.Context, m Match) error {
+	return r.Process(ctx, m.Tx, m.Client)
+}
+
+// respond builds, signs and sends the response with nonce. It returns the
+// sent tx, or nil on a dry run.
+func (r *Responder) respond(ctx context.Context, client TxClient, key *ecdsa.PrivateKey, from common.Address, nonce uint64) (*types.Transaction, types.Signer, error) {
+	to := *r.To
+
+	chainID := r.ChainID
+	if chainID == nil {
+		var err error
+		if chainID, err = client.ChainID(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	value := r.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	data := r.Data
+
+	gas, err := r.estimateGas(ctx, client, from, to, value, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tx *types.Transaction
+	switch r.TxType {
+	case TxDynamic:
+		tx, err = r.dynamicFeeTx(ctx, client, chainID, nonce, to, value, gas, data)
+	default:
+		tx, err = r.legacyTx(ctx, client, nonce, to, value, gas, data)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkFunds(ctx, client, from, tx); err != nil {
+		return nil, nil, err
+	}
+	if r.Simulate {
+		if err := simulate(ctx, client, from, tx); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	signer := types.LatestSignerForChainID(chainID)
+	tx, err = types.SignTx(tx, signer, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if r.DryRun {
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, nil, err
+		}
+		slog.Info("Dry run, not sending tx", "hash", tx.Hash(), "raw", hexutil.Bytes(raw))
+		return nil, nil, nil
+	}
+	r.printRaw(tx)
+
+	if err := r.send(ctx, client, tx); err != nil {
+		slog.Error("Send tx failed", "hash", tx.Hash(), "err", err)
+		return nil, nil, err
+	}
+
+	slog.Info("Sent response tx", "hash", tx.Hash(), "from", from, "to", tx.To())
+	return tx, signer, nil
+}
+
+// checkFunds makes sure from can pay for tx, its value plus the gas at the
+// highest price it may pay, rather than leaving it to the node to reject.
+func checkFunds(ctx context.Context, client TxClient, from common.Address, tx *types.Transaction) error {
+	balance, err := client.BalanceAt(ctx, from, nil)
+	if err != nil {
+		return err
+	}
+	if cost := tx.Cost(); balance.Cmp(cost) < 0 {
+		slog.Warn("Insufficient funds for the response, not sending", "from", from,
+			"balance", FormatEther(balance), "cost", FormatEther(cost))
+		return ErrNoFunds
+	}
+	return nil
+}
+
+// printRaw logs the encoding of the signed tx if PrintRaw is set.
+func (r *Responder) printRaw(tx *types.Transaction) {
+	if !r.PrintRaw {
+		return
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		slog.Error("Encode tx", "hash", tx.Hash(), "err", err)
+		return
+	}
+	slog.Info("Signed tx", "hash", tx.Hash(), "raw", hexutil.Bytes(raw))
+}
+
+// estimateGas estimates the gas of the response plus GasPadding percent.
+// A plain transfer falls back to params.TxGas if the estimate fails.
+func (r *Responder) estimateGas(ctx context.Context, client TxClient, from, to common.Address, value *big.Int, data []byte) (uint64, error) {
+	gas, err := client.EstimateGas(ctx, ethereum.CallMsg{
+		From:  from,
+		To:    &to,
+		Value: value,
+		Data:  data,
+	})
+	if err != nil {
+		if len(data) == 0 {
+			slog.Warn("Estimate gas failed, using the transfer gas", "gas", params.TxGas, "err", err)
+			return params.TxGas, nil
+		}
+		return 0, err
+	}
+
+	gas += gas * uint64(r.GasPadding) / 100
+	slog.Debug("Estimated gas", "gas", gas)
+	return gas, nil
+}
+
+// legacyTx builds an unsigned legacy tx at the suggested gas price.
+func (r *Responder) legacyTx(ctx context.Context, client TxClient, nonce uint64, to common.Address, value *big.Int, gas uint64, data []byte) (*types.Transaction, error) {
+	price, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.MaxGasPrice != nil && price.Cmp(r.MaxGasPrice) > 0 {
+		slog.Warn("Suggested gas price exceeds maximum, not sending", "gasPrice", price, "max", r.MaxGasPrice)
+		return nil, ErrGasPriceTooHigh
+	}
+
+	return types.NewTransaction(nonce, to, value, gas, price, data), nil
+}
+
+// dynamicFeeTx builds an unsigned EIP-1559 tx paying the suggested tip on
+// top of twice the latest base fee, the fee cap is limited to MaxGasPrice.
+func (r *Responder) dynamicFeeTx(ctx context.Context, client TxClient, chainID *big.Int, nonce uint64, to common.Address, value *big.Int, gas uint64, data []byte) (*types.Transaction, error) {
+	tip, err := client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if head.BaseFee == nil {
+		return nil, errors.New("node does not support EIP-1559 transactions")
+	}
+
+	feeCap := new(big.Int).Mul(head.BaseFee, big.NewInt(2))
+	feeCap.Add(feeCap, tip)
+
+	if r.MaxGasPrice != nil {
+		if price := new(big.Int).Add(head.BaseFee, tip); price.Cmp(r.MaxGasPrice) > 0 {
+			slog.Warn("Suggested gas price exceeds maximum, not sending", "gasPrice", price, "max", r.MaxGasPrice)
+			return nil, ErrGasPriceTooHigh
+		}
+		if feeCap.Cmp(r.MaxGasPrice) > 0 {
+			feeCap.Set(r.MaxGasPrice)
+		}
+	}
+
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: feeCap,
+		Gas:       gas,
+		To:        &to,
+		Value:     value,
+		Data:      data,
+	}), nil
+}
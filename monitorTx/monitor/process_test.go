@@ -0,0 +1,181 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// responseTo receives the responses of the tests.
+var responseTo = common.Address{0xaa}
+
+func TestProcessSend(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	for _, txType := range []string{TxLegacy, TxDynamic} {
+		client := newFakeClient()
+		client.nonce = 7
+		r := &Responder{Key: key, To: &responseTo, TxType: txType}
+
+		if err := r.Process(context.Background(), valueTx(0, nil), client); err != nil {
+			t.Fatalf("%s: %v", txType, err)
+		}
+		if len(client.sent) != 1 {
+			t.Fatalf("%s: sent %d txs, want 1", txType, len(client.sent))
+		}
+
+		tx := client.sent[0]
+		if sender, err := Sender(client.chainID, tx); err != nil || sender != from {
+			t.Errorf("%s: signed by %x, %v, want %x", txType, sender, err, from)
+		}
+		if tx.Nonce() != 7 || tx.Gas() != 21000 {
+			t.Errorf("%s: got nonce %d gas %d", txType, tx.Nonce(), tx.Gas())
+		}
+		if *tx.To() != responseTo || tx.Value().Sign() != 0 {
+			t.Errorf("%s: sent %v to %x", txType, tx.Value(), tx.To())
+		}
+
+		switch txType {
+		case TxLegacy:
+			if tx.Type() != types.LegacyTxType || tx.GasPrice().Cmp(client.gasPrice) != 0 {
+				t.Errorf("legacy: got type %d price %v", tx.Type(), tx.GasPrice())
+			}
+		case TxDynamic:
+			// twice the base fee plus the tip
+			if tx.Type() != types.DynamicFeeTxType || tx.GasFeeCap().Cmp(big.NewInt(gwei(41))) != 0 {
+				t.Errorf("dynamic: got type %d fee cap %v", tx.Type(), tx.GasFeeCap())
+			}
+		}
+	}
+}
+
+func TestProcessNotSent(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+
+	tests := []struct {
+		name string
+		r    *Responder
+		want error
+	}{
+		{"no key", &Responder{}, ErrNoKey},
+		{"no recipient", &Responder{Key: key}, ErrNoRecipient},
+		{"dry run", &Responder{Key: key, To: &responseTo, DryRun: true}, nil},
+		{"legacy too expensive", &Responder{Key: key, To: &responseTo, MaxGasPrice: big.NewInt(gwei(5))}, ErrGasPriceTooHigh},
+		{"dynamic too expensive", &Responder{Key: key, To: &responseTo, TxType: TxDynamic, MaxGasPrice: big.NewInt(gwei(20))}, ErrGasPriceTooHigh},
+	}
+
+	for _, tt := range tests {
+		client := newFakeClient()
+		if err := tt.r.Process(context.Background(), valueTx(0, nil), client); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+		if len(client.sent) != 0 {
+			t.Errorf("%s: sent %d txs", tt.name, len(client.sent))
+		}
+	}
+}
+
+// revertError is an eth_call failure carrying revert data, as the node
+// returns it.
+type revertError struct{ data string }
+
+func (e revertError) Error() string          { return "execution reverted" }
+func (e revertError) ErrorData() interface{} { return e.data }
+
+func TestProcessSimulate(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+
+	// Error("not allowed")
+	reason := "0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"000000000000000000000000000000000000000000000000000000000000000b" +
+		hexutil.Encode([]byte("not allowed"))[2:] + "000000000000000000000000000000000000000000"
+
+	tests := []struct {
+		name    string
+		callErr error
+		reason  string
+	}{
+		{"succeeds", nil, ""},
+		{"reason", revertError{reason}, "not allowed"},
+		{"no reason", errors.New("execution reverted"), "execution reverted"},
+	}
+
+	for _, tt := range tests {
+		client := newFakeClient()
+		client.callErr = tt.callErr
+
+		r := &Responder{Key: key, To: &responseTo, Simulate: true}
+		err := r.Process(context.Background(), valueTx(0, nil), client)
+		if tt.callErr == nil {
+			if err != nil || len(client.sent) != 1 {
+				t.Errorf("%s: got %v, sent %d txs", tt.name, err, len(client.sent))
+			}
+			continue
+		}
+		if !errors.Is(err, ErrReverted) || len(client.sent) != 0 {
+			t.Errorf("%s: got %v, sent %d txs, want ErrReverted", tt.name, err, len(client.sent))
+		}
+		if got := RevertReason(tt.callErr); got != tt.reason {
+			t.Errorf("%s: reason %q, want %q", tt.name, got, tt.reason)
+		}
+	}
+}
+
+func TestProcessCall(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	client := newFakeClient()
+
+	data := []byte{0xa9, 0x05, 0x9c, 0xbb}
+	r := &Responder{Key: key, To: &responseTo, Value: big.NewInt(1000), Data: data}
+	if err := r.Process(context.Background(), valueTx(0, nil), client); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.sent) != 1 {
+		t.Fatalf("sent %d txs, want 1", len(client.sent))
+	}
+	tx := client.sent[0]
+	if *tx.To() != responseTo || tx.Value().Int64() != 1000 || hexutil.Encode(tx.Data()) != "0xa9059cbb" {
+		t.Errorf("sent %v with %x to %x", tx.Value(), tx.Data(), tx.To())
+	}
+}
+
+func TestProcessNoFunds(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+
+	for _, txType := range []string{TxLegacy, TxDynamic} {
+		client := newFakeClient()
+		client.balance = new(big.Int)
+
+		r := &Responder{Key: key, To: &responseTo, TxType: txType}
+		if err := r.Process(context.Background(), valueTx(0, nil), client); !errors.Is(err, ErrNoFunds) {
+			t.Errorf("%s: got %v, want ErrNoFunds", txType, err)
+		}
+		if len(client.sent) != 0 {
+			t.Errorf("%s: sent %d txs without funds", txType, len(client.sent))
+		}
+	}
+}
+
+func TestProcessCancelled(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	client := newFakeClient()
+	r := &Responder{Key: key, To: &responseTo}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := r.Process(ctx, valueTx(0, nil), client); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+	if len(client.sent) != 0 {
+		t.Errorf("sent %d txs after cancel", len(client.sent))
+	}
+}
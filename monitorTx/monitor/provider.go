@@ -0,0 +1,104 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Providers select how pending txs are subscribed to, see Monitor.Provider.
+const (
+	// ProviderAuto picks the provider from the endpoint URL.
+	ProviderAuto = "auto"
+	// ProviderAlchemy subscribes to alchemy_pendingTransactions, filtered
+	// by the watched addresses and carrying the full txs.
+	ProviderAlchemy = "alchemy"
+	// ProviderGeneric subscribes to newPendingTransactions and fetches
+	// every announced tx.
+	ProviderGeneric = "generic"
+)
+
+// ParseProvider checks s names a provider, "" being ProviderAuto.
+func ParseProvider(s string) (string, error) {
+	switch s {
+	case "", ProviderAuto:
+		return ProviderAuto, nil
+	case ProviderAlchemy, ProviderGeneric:
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown provider %q, want %s, %s or %s", s, ProviderAuto, ProviderAlchemy, ProviderGeneric)
+}
+
+// DetectProvider returns the provider serving endpoint, ProviderAlchemy for
+// the alchemy.com hosts and ProviderGeneric for any other.
+func DetectProvider(endpoint string) string {
+	if u, err := url.Parse(endpoint); err == nil {
+		host := strings.ToLower(u.Hostname())
+		if host == "alchemy.com" || strings.HasSuffix(host, ".alchemy.com") {
+			return ProviderAlchemy
+		}
+	}
+	return ProviderGeneric
+}
+
+// provider returns the provider of the current endpoint.
+func (m *Monitor) provider() string {
+	if m.Provider == "" || m.Provider == ProviderAuto {
+		return DetectProvider(m.URL)
+	}
+	return m.Provider
+}
+
+// alchemyFilter is the filter of alchemy_pendingTransactions: the txs from
+// or to the given addresses, those of either list on Alchemy.
+type alchemyFilter struct {
+	FromAddress []common.Address `json:"fromAddress,omitempty"`
+	ToAddress   []common.Address `json:"toAddress,omitempty"`
+	HashesOnly  bool             `json:"hashesOnly"`
+}
+
+// alchemyFilter filters on the watched addresses of the side Match picks.
+// Prefixes cannot be told to Alchemy, with any it is sent every tx.
+func (m *Monitor) alchemyFilter() alchemyFilter {
+	var f alchemyFilter
+	if len(m.Prefixes) > 0 {
+		return f
+	}
+	if m.Match == MatchFrom || m.Match == MatchEither {
+		f.FromAddress = addressList(m.Senders)
+	}
+	if m.Match == MatchTo || m.Match == MatchEither {
+		f.ToAddress = addressList(m.Recipients)
+	}
+	return f
+}
+
+func addressList(set map[common.Address]struct{}) []common.Address {
+	list := make([]common.Address, 0, len(set))
+	for addr := range set {
+		list = append(list, addr)
+	}
+	return list
+}
+
+// subscribePending subscribes to the pending txs announced by the
+// provider: the full txs matching the watched addresses on Alchemy, falling
+// back to the hashes of every tx when that fails.
+func (m *Monitor) subscribePending(ctx context.Context, rpccli *rpc.Client, f feeds) (*rpc.ClientSubscription, error) {
+	if m.provider() == ProviderAlchemy {
+		sub, err := rpccli.EthSubscribe(ctx, f.txs, "alchemy_pendingTransactions", m.alchemyFilter())
+		if err == nil {
+			return sub, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		slog.Warn("Alchemy subscription failed, subscribing to all pending txs", "endpoint", m.URL, "err", err)
+	}
+	return rpccli.EthSubscribe(ctx, f.hashes, "newPendingTransactions")
+}
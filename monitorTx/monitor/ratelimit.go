@@ -0,0 +1,34 @@
+package monitor
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// limitExceededCode is the JSON-RPC error code providers such as Infura
+// answer with once a quota is used up.
+const limitExceededCode = -32005
+
+// IsRateLimited reports whether err is a provider rejecting a call for
+// exceeding its request quota.
+func IsRateLimited(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == limitExceededCode {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "too many requests") || strings.Contains(msg, "rate limit")
+}
@@ -0,0 +1,119 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TxRecord is the machine readable form of a matched transaction.
+type TxRecord struct {
+	Hash     common.Hash     `json:"hash"`
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"`
+	Value    *big.Int        `json:"value"`
+	Gas      uint64          `json:"gas"`
+	GasPrice *big.Int        `json:"gasPrice"`
+	Nonce    uint64          `json:"nonce"`
+	Input    hexutil.Bytes   `json:"input"`
+
+	// ValueUSD is the worth of Value when a price is known, see PriceFeed.
+	ValueUSD *float64 `json:"valueUsd,omitempty"`
+
+	// Transfer is set when the input is an ERC-20 transfer.
+	Transfer *TokenTransfer `json:"transfer,omitempty"`
+
+	// Call is set when the input is decoded by a user supplied ABI.
+	Call *Call `json:"call,omitempty"`
+
+	// Tip is set for txs with a fee cap, which GasPrice is then. Their
+	// EffectiveGasPrice needs the base fee, see EffectiveGasPrice.
+	Tip               *big.Int `json:"tip,omitempty"`
+	EffectiveGasPrice *big.Int `json:"effectiveGasPrice,omitempty"`
+
+	// AccessList is the EIP-2930 access list of the tx, if any.
+	AccessList types.AccessList `json:"accessList,omitempty"`
+
+	// Contract is the address a contract creation deploys to.
+	Contract *common.Address `json:"contractAddress,omitempty"`
+
+	// FromLabel and ToLabel name From and To if they are known, see Label.
+	FromLabel string `json:"fromLabel,omitempty"`
+	ToLabel   string `json:"toLabel,omitempty"`
+
+	// Raw is the tx as returned by the node, see Monitor.RunRaw.
+	Raw json.RawMessage `json:"raw,omitempty"`
+
+	// DataLimit limits the input in the JSON form as FormatData does.
+	// NewTxRecord sets it to DataUnlimited.
+	DataLimit int `json:"-"`
+}
+
+func NewTxRecord(tx *types.Transaction, from common.Address) *TxRecord {
+	transfer, _ := DecodeTokenTransfer(tx.Data())
+
+	rec := &TxRecord{
+		Hash:     tx.Hash(),
+		From:     from,
+		To:       tx.To(),
+		Value:    tx.Value(),
+		Gas:      tx.Gas(),
+		GasPrice: tx.GasPrice(),
+		Nonce:    tx.Nonce(),
+		Input:    tx.Data(),
+		Transfer: transfer,
+
+		AccessList: tx.AccessList(),
+
+		DataLimit: DataUnlimited,
+	}
+	if tx.To() == nil {
+		addr := crypto.CreateAddress(from, tx.Nonce())
+		rec.Contract = &addr
+	}
+	if hasFeeCap(tx) {
+		rec.Tip = tx.GasTipCap()
+	}
+	return rec
+}
+
+// Label sets FromLabel and ToLabel from labels.
+func (rec *TxRecord) Label(labels Labels) {
+	rec.FromLabel = labels[rec.From]
+	if rec.To != nil {
+		rec.ToLabel = labels[*rec.To]
+	}
+}
+
+// MarshalJSON encodes rec with its input limited to DataLimit bytes.
+func (rec TxRecord) MarshalJSON() ([]byte, error) {
+	type plain TxRecord
+	return json.Marshal(struct {
+		plain
+		Input string `json:"input,omitempty"`
+	}{plain(rec), FormatData(rec.Input, rec.DataLimit)})
+}
+
+// DefaultDataLimit is the number of input bytes shown of a matched tx.
+const DefaultDataLimit = 256
+
+// DataUnlimited is the data limit showing all of the input.
+const DataUnlimited = -1
+
+// FormatData hex encodes the first limit bytes of data, noting the size of
+// what is cut off. A limit of 0 omits data, returning "", and a negative
+// one shows all of it.
+func FormatData(data []byte, limit int) string {
+	switch {
+	case limit == 0:
+		return ""
+	case limit < 0 || len(data) <= limit:
+		return hexutil.Encode(data)
+	}
+	return fmt.Sprintf("%s…(truncated, total=%d bytes)", hexutil.Encode(data[:limit]), len(data))
+}
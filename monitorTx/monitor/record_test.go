@@ -0,0 +1,69 @@
+package monitor
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestNewTxRecordContract(t *testing.T) {
+	from := common.HexToAddress("0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0")
+
+	creation := types.NewContractCreation(1, big.NewInt(0), 53000, big.NewInt(1), nil)
+	rec := NewTxRecord(creation, from)
+	want := common.HexToAddress("0x343c43a37d37dff08ae8c4a11544c718abb4fcf8")
+	if rec.Contract == nil || *rec.Contract != want {
+		t.Errorf("contract %v, want %x", rec.Contract, want)
+	}
+
+	if rec := NewTxRecord(valueTx(0, nil), from); rec.Contract != nil {
+		t.Errorf("call has contract %x", *rec.Contract)
+	}
+}
+
+func TestFormatData(t *testing.T) {
+	data := []byte{0xa9, 0x05, 0x9c, 0xbb, 0x01, 0x02}
+	tests := []struct {
+		limit int
+		want  string
+	}{
+		{DataUnlimited, "0xa9059cbb0102"},
+		{6, "0xa9059cbb0102"},
+		{4, "0xa9059cbb…(truncated, total=6 bytes)"},
+		{0, ""},
+	}
+	for _, tt := range tests {
+		if got := FormatData(data, tt.limit); got != tt.want {
+			t.Errorf("limit %d: got %q, want %q", tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestTxRecordJSONDataLimit(t *testing.T) {
+	rec := NewTxRecord(valueTx(0, []byte{1, 2, 3}), common.Address{})
+
+	for limit, want := range map[int]interface{}{
+		DataUnlimited: "0x010203",
+		2:             "0x0102…(truncated, total=3 bytes)",
+		0:             nil,
+	} {
+		rec.DataLimit = limit
+		b, err := json.Marshal(rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(b, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if got := decoded["input"]; got != want {
+			t.Errorf("limit %d: input %v, want %v", limit, got, want)
+		}
+		if decoded["hash"] != rec.Hash.Hex() {
+			t.Errorf("limit %d: hash %v", limit, decoded["hash"])
+		}
+	}
+}
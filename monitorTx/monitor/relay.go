@@ -0,0 +1,71 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultRelayTimeout bounds a single relayed send.
+const DefaultRelayTimeout = 5 * time.Second
+
+// Relay rebroadcasts matched txs, as signed by their sender, to another
+// node such as a private mempool or a second provider.
+type Relay struct {
+	URL     string
+	Timeout time.Duration
+
+	client *rpc.Client
+}
+
+// DialRelay connects to the node at url, sending header with every request
+// over http and ws.
+func DialRelay(ctx context.Context, url string, header http.Header) (*Relay, error) {
+	client, err := dial(ctx, url, header)
+	if err != nil {
+		return nil, fmt.Errorf("dial relay %s: %w", url, err)
+	}
+	return &Relay{URL: url, Timeout: DefaultRelayTimeout, client: client}, nil
+}
+
+// Handle makes r a Handler, it relays the tx of m.
+func (r *Relay) Handle(ctx context.Context, m Match) error {
+	return r.Send(ctx, m.Tx)
+}
+
+// Send hands the raw tx to the node with eth_sendRawTransaction. A node
+// already holding it counts as success.
+func (r *Relay) Send(ctx context.Context, tx *types.Transaction) error {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	err = r.client.CallContext(ctx, nil, "eth_sendRawTransaction", hexutil.Encode(raw))
+	if err != nil && isKnownTx(err) {
+		slog.Debug("Relayed tx already known", "hash", tx.Hash(), "relay", r.URL)
+		return nil
+	}
+	if err != nil {
+		observeRPCError(CallRelay, err)
+		return fmt.Errorf("relay %s: %w", r.URL, err)
+	}
+	slog.Info("Relayed tx", "hash", tx.Hash(), "relay", r.URL)
+	return nil
+}
+
+// Close disconnects from the node.
+func (r *Relay) Close() {
+	r.client.Close()
+}
@@ -0,0 +1,124 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// relayNode takes raw txs, answering err once it holds them.
+type relayNode struct {
+	mu  sync.Mutex
+	raw []hexutil.Bytes
+	err error
+}
+
+func (n *relayNode) SendRawTransaction(raw hexutil.Bytes) (common.Hash, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.raw = append(n.raw, raw)
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return common.Hash{}, err
+	}
+	return tx.Hash(), n.err
+}
+
+func (n *relayNode) fail(err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.err = err
+}
+
+func TestRelay(t *testing.T) {
+	node := &relayNode{}
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", node); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	r, err := DialRelay(context.Background(), ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: 3, Gas: 21000, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), To: &common.Address{1}})
+	if err := r.Handle(context.Background(), Match{Tx: tx}); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := tx.MarshalBinary()
+	if len(node.raw) != 1 || string(node.raw[0]) != string(want) {
+		t.Fatalf("relayed %x, want %x", node.raw, want)
+	}
+
+	node.fail(errors.New("already known"))
+	if err := r.Send(context.Background(), tx); err != nil {
+		t.Errorf("known tx: %v, want success", err)
+	}
+	node.fail(errors.New("nonce too low"))
+	if err := r.Send(context.Background(), tx); err == nil {
+		t.Error("rejected tx relayed without error")
+	}
+}
+
+func TestRelayHeaders(t *testing.T) {
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", &relayNode{}); err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var got []http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		got = append(got, r.Header.Clone())
+		mu.Unlock()
+		server.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	// the pool of the watched endpoints carries their credentials
+	pool, err := NewEndpointPool([]string{"ws://watch.example.org"}, PolicyFailover)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.Header = http.Header{"Authorization": {"Bearer watch"}}
+
+	r, err := DialRelay(context.Background(), ts.URL, http.Header{"X-Relay-Key": {"relay"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), Gas: 21000, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), To: &common.Address{1}})
+	if err := r.Send(context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) == 0 {
+		t.Fatal("relay got no request")
+	}
+	for _, h := range got {
+		if auth := h.Get("Authorization"); auth != "" {
+			t.Errorf("relay got the watch header Authorization: %s", auth)
+		}
+		if key := h.Get("X-Relay-Key"); key != "relay" {
+			t.Errorf("relay header %q, want relay", key)
+		}
+	}
+}
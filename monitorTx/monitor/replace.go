@@ -0,0 +1,130 @@
+package monitor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// DefaultMaxBumps caps the replacements of a stuck response.
+	DefaultMaxBumps = 3
+
+	// bumpPercent raises the gas price of a replacement, nodes require
+	// at least 10%.
+	bumpPercent = 20
+
+	// receiptPollInterval paces the receipt lookups of a sent response.
+	receiptPollInterval = 2 * time.Second
+)
+
+// confirm waits ReplaceAfter for tx to be mined and, while it isn't, resends
+// it with the same nonce at a bumped gas price, at most MaxBumps times.
+// Any of the sent txs being mined confirms the response.
+func (r *Responder) confirm(ctx context.Context, client TxClient, signer types.Signer, key *ecdsa.PrivateKey, tx *types.Transaction) error {
+	sent := []*types.Transaction{tx}
+
+	for bumps := 0; ; bumps++ {
+		mined, err := waitMined(ctx, client, sent, r.ReplaceAfter)
+		if err != nil {
+			return err
+		}
+		if mined != nil {
+			slog.Info("Response tx mined", "hash", mined.Hash())
+			return nil
+		}
+
+		if bumps >= r.MaxBumps {
+			slog.Warn("Response tx not mined, giving up", "hash", tx.Hash(), "bumps", bumps)
+			return nil
+		}
+
+		next := bumpTx(tx, bumpPercent)
+		if r.MaxGasPrice != nil && next.GasFeeCap().Cmp(r.MaxGasPrice) > 0 {
+			slog.Warn("Bumped gas price exceeds maximum, not replacing", "hash", tx.Hash(), "gasPrice", next.GasFeeCap(), "max", r.MaxGasPrice)
+			return ErrGasPriceTooHigh
+		}
+
+		if next, err = types.SignTx(next, signer, key); err != nil {
+			return err
+		}
+		r.printRaw(next)
+		if err := r.send(ctx, client, next); err != nil {
+			return err
+		}
+
+		slog.Info("Replaced response tx", "hash", tx.Hash(), "replacement", next.Hash(), "gasPrice", next.GasFeeCap())
+		tx = next
+		sent = append(sent, tx)
+	}
+}
+
+// waitMined polls the receipts of txs for up to timeout and returns the one
+// mined, or nil if none was.
+func waitMined(ctx context.Context, client TxClient, txs []*types.Transaction, timeout time.Duration) (*types.Transaction, error) {
+	interval := receiptPollInterval
+	if timeout < interval {
+		interval = timeout
+	}
+	deadline := time.Now().Add(timeout)
+
+	for {
+		for _, tx := range txs {
+			_, err := client.TransactionReceipt(ctx, tx.Hash())
+			if err == nil {
+				return tx, nil
+			}
+			if !errors.Is(err, ethereum.NotFound) {
+				return nil, err
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return nil, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// bumpTx returns an unsigned copy of tx with its gas prices raised by
+// percent, rounding up so small prices still increase.
+func bumpTx(tx *types.Transaction, percent int64) *types.Transaction {
+	bump := func(v *big.Int) *big.Int {
+		n := new(big.Int).Mul(v, big.NewInt(100+percent))
+		n.Add(n, big.NewInt(99))
+		return n.Div(n, big.NewInt(100))
+	}
+
+	if tx.Type() == types.DynamicFeeTxType {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  bump(tx.GasTipCap()),
+			GasFeeCap:  bump(tx.GasFeeCap()),
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		})
+	}
+
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    tx.Nonce(),
+		GasPrice: bump(tx.GasPrice()),
+		Gas:      tx.Gas(),
+		To:       tx.To(),
+		Value:    tx.Value(),
+		Data:     tx.Data(),
+	})
+}
@@ -0,0 +1,37 @@
+package monitor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBumpTx(t *testing.T) {
+	to := common.HexToAddress("0x003be5Df5FeF651EF0C59cD175c73ca1415f53eA")
+
+	legacy := bumpTx(types.NewTransaction(3, to, big.NewInt(1000), 21000, big.NewInt(100), nil), 20)
+	if legacy.GasPrice().Cmp(big.NewInt(120)) != 0 || legacy.Nonce() != 3 || *legacy.To() != to {
+		t.Errorf("legacy: got price %v nonce %d", legacy.GasPrice(), legacy.Nonce())
+	}
+
+	// 1 wei still goes up
+	if tiny := bumpTx(types.NewTransaction(0, to, nil, 21000, big.NewInt(1), nil), 20); tiny.GasPrice().Cmp(big.NewInt(2)) != 0 {
+		t.Errorf("tiny: got price %v, want 2", tiny.GasPrice())
+	}
+
+	dynamic := bumpTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     5,
+		GasTipCap: big.NewInt(10),
+		GasFeeCap: big.NewInt(250),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(1000),
+	}), 20)
+	if dynamic.Type() != types.DynamicFeeTxType || dynamic.GasTipCap().Cmp(big.NewInt(12)) != 0 ||
+		dynamic.GasFeeCap().Cmp(big.NewInt(300)) != 0 || dynamic.Nonce() != 5 || dynamic.ChainId().Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("dynamic: got tip %v cap %v nonce %d", dynamic.GasTipCap(), dynamic.GasFeeCap(), dynamic.Nonce())
+	}
+}
@@ -0,0 +1,61 @@
+package monitor
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// nonceMemory is the number of recent (sender, nonce) pairs remembered to
+// spot replacements.
+const nonceMemory = 4096
+
+type senderNonce struct {
+	from  common.Address
+	nonce uint64
+}
+
+// sentTx is what is remembered of the last tx of a sender nonce.
+type sentTx struct {
+	hash     common.Hash
+	gasPrice *big.Int
+	tip      *big.Int
+}
+
+// sentNonces remembers the last tx of the most recent sender nonces, the
+// oldest is evicted once nonceMemory are held. It is safe for concurrent
+// use.
+type sentNonces struct {
+	mu   sync.Mutex
+	ring []senderNonce
+	next int
+	last map[senderNonce]sentTx
+}
+
+// replaces records tx as the last one of its nonce sent by from and
+// returns the different tx of the same nonce seen before, if any.
+func (s *sentNonces) replaces(from common.Address, tx *types.Transaction) (sentTx, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.last == nil {
+		s.last = make(map[senderNonce]sentTx, nonceMemory)
+		s.ring = make([]senderNonce, 0, nonceMemory)
+	}
+
+	key := senderNonce{from, tx.Nonce()}
+	prev, seen := s.last[key]
+	if !seen {
+		if len(s.ring) < cap(s.ring) {
+			s.ring = append(s.ring, key)
+		} else {
+			delete(s.last, s.ring[s.next])
+			s.ring[s.next] = key
+			s.next = (s.next + 1) % len(s.ring)
+		}
+	}
+	s.last[key] = sentTx{hash: tx.Hash(), gasPrice: tx.GasPrice(), tip: tx.GasTipCap()}
+	return prev, seen && prev.hash != tx.Hash()
+}
@@ -0,0 +1,55 @@
+package monitor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestSentNoncesReplaces(t *testing.T) {
+	var s sentNonces
+	from, other := common.Address{1}, common.Address{2}
+	tx := func(nonce uint64, price int64) *types.Transaction {
+		return types.NewTransaction(nonce, common.Address{9}, big.NewInt(0), 21000, big.NewInt(price), nil)
+	}
+
+	first, bumped := tx(5, 10), tx(5, 12)
+	if _, ok := s.replaces(from, first); ok {
+		t.Fatal("first tx of a nonce is a replacement")
+	}
+	if _, ok := s.replaces(from, first); ok {
+		t.Error("re-announced tx is a replacement")
+	}
+	if _, ok := s.replaces(other, bumped); ok {
+		t.Error("same nonce of another sender is a replacement")
+	}
+
+	prev, ok := s.replaces(from, bumped)
+	if !ok {
+		t.Fatal("same nonce tx not flagged")
+	}
+	if prev.hash != first.Hash() || prev.gasPrice.Int64() != 10 {
+		t.Errorf("replaces %x at %v, want %x at 10", prev.hash, prev.gasPrice, first.Hash())
+	}
+
+	// the bumped tx is now the one a further replacement replaces
+	if prev, ok := s.replaces(from, tx(5, 15)); !ok || prev.hash != bumped.Hash() {
+		t.Errorf("second replacement: %x, %v", prev.hash, ok)
+	}
+}
+
+func TestSentNoncesEvicts(t *testing.T) {
+	var s sentNonces
+	from := common.Address{1}
+	for n := uint64(0); n <= nonceMemory; n++ {
+		s.replaces(from, types.NewTransaction(n, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil))
+	}
+	if len(s.last) != nonceMemory {
+		t.Errorf("%d nonces remembered, want %d", len(s.last), nonceMemory)
+	}
+	if _, ok := s.last[senderNonce{from, 0}]; ok {
+		t.Error("oldest nonce not evicted")
+	}
+}
@@ -0,0 +1,59 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Replay fetches the txs of the hashes read from r, one per line, and
+// runs them through the same matching as Run. Blank lines and lines
+// starting with # are skipped, as are hashes the node doesn't know. It
+// waits for the handlers and returns how many hashes were read and how
+// many of them matched.
+func (m *Monitor) Replay(ctx context.Context, r io.Reader, handler func(*types.Transaction)) (read, matched int, err error) {
+	var count atomic.Int64
+	counted := func(tx *types.Transaction) {
+		count.Add(1)
+		handler(tx)
+	}
+
+	m.refreshHead(ctx)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if ctx.Err() != nil {
+			break
+		}
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		b, err := GetHexStringBytes(text)
+		if err == nil && len(b) != common.HashLength {
+			err = fmt.Errorf("%q is no tx hash", text)
+		}
+		if err != nil {
+			return read, int(count.Load()), fmt.Errorf("line %d: %v", line, err)
+		}
+		h := common.BytesToHash(b)
+		read++
+
+		tx, err := fetchTx(ctx, m.Client(), h, m.FetchTimeout)
+		if err != nil {
+			slog.Warn("Fetch tx failed, skipped", "hash", h, "err", err)
+			continue
+		}
+		m.dispatch(ctx, tx, counted)
+	}
+	m.wg.Wait()
+	return read, int(count.Load()), scanner.Err()
+}
@@ -0,0 +1,68 @@
+package monitor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestReplay(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+
+	client := newFakeClient()
+	var txs []*types.Transaction
+	for i, k := range []*ecdsa.PrivateKey{key, other, key} {
+		tx, err := types.SignTx(types.NewTransaction(uint64(i), common.Address{1}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pending[tx.Hash()] = tx
+		txs = append(txs, tx)
+	}
+
+	m := &Monitor{
+		Senders: AddressSet([]common.Address{crypto.PubkeyToAddress(key.PublicKey)}),
+		Match:   MatchFrom,
+		ChainID: client.chainID,
+		client:  client,
+	}
+
+	input := strings.Join([]string{
+		"# known txs",
+		txs[0].Hash().Hex(),
+		"",
+		txs[1].Hash().Hex(),
+		"  " + txs[2].Hash().Hex() + "  ",
+		common.Hash{9}.Hex(),
+	}, "\n")
+
+	var mu sync.Mutex
+	got := make(map[common.Hash]bool)
+	read, matched, err := m.Replay(context.Background(), strings.NewReader(input), func(tx *types.Transaction) {
+		mu.Lock()
+		defer mu.Unlock()
+		got[tx.Hash()] = true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read != 4 || matched != 2 {
+		t.Errorf("read %d, matched %d, want 4 and 2", read, matched)
+	}
+	if !got[txs[0].Hash()] || !got[txs[2].Hash()] || got[txs[1].Hash()] {
+		t.Errorf("handled %v", got)
+	}
+
+	if _, _, err := m.Replay(context.Background(), strings.NewReader("0x1234\n"), func(*types.Transaction) {}); err == nil {
+		t.Error("short hash replayed")
+	}
+}
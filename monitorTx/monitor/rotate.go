@@ -0,0 +1,130 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// RotatingFile is an append-only file that is renamed to path.1 once it
+// would grow past maxSize bytes, shifting the older ones to path.2 and so
+// on. Only the newest keep rotated files are kept; with keep 0 the file is
+// truncated and starts over.
+// A single write is never split, so a file may exceed maxSize by the size
+// of the write that started it.
+type RotatingFile struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	keep    int
+	header  []byte
+	file    *os.File
+	size    int64
+}
+
+// OpenRotatingFile opens path for appending, creating it if needed. Every
+// file that starts out empty begins with header, such as a CSV header row.
+func OpenRotatingFile(path string, maxSize int64, keep int, header []byte) (*RotatingFile, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("rotate size %d is not positive", maxSize)
+	}
+	r := &RotatingFile{path: path, maxSize: maxSize, keep: keep, header: header}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens path and writes the header if it is empty.
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.file, r.size = f, info.Size()
+
+	if r.size == 0 && len(r.header) > 0 {
+		n, err := f.Write(r.header)
+		r.size += int64(n)
+		return err
+	}
+	return nil
+}
+
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	if r.size+int64(len(p)) > r.maxSize && r.size > int64(len(r.header)) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the old files by one, moves the current one to path.1 and
+// starts a new one.
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	r.file = nil
+
+	if r.keep > 0 {
+		if err := removeIfExists(fmt.Sprintf("%s.%d", r.path, r.keep)); err != nil {
+			return err
+		}
+		for i := r.keep - 1; i >= 1; i-- {
+			if err := renameIfExists(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1)); err != nil {
+				return err
+			}
+		}
+		if err := os.Rename(r.path, r.path+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Remove(r.path); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+// Close closes the current file.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func renameIfExists(from, to string) error {
+	if err := os.Rename(from, to); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+	r, err := OpenRotatingFile(path, 10, 2, []byte("h\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	// every line but the first starts a new file, the oldest is dropped
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, want := range map[string]string{
+		path:        "h\nfourth\n",
+		path + ".1": "h\nthird\n",
+		path + ".2": "h\nsecond\n",
+	} {
+		got, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s holds %q, want %q", filepath.Base(name), got, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("kept a third old file: %v", err)
+	}
+}
+
+func TestRotatingFileKeepNone(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.json")
+	if err := os.WriteFile(path, []byte("earlier\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenRotatingFile(path, 12, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	// appends to the existing file, then starts over
+	for _, line := range []string{"one\n", "two\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, _ := os.ReadFile(path); string(got) != "two\n" {
+		t.Errorf("file holds %q, want %q", got, "two\n")
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Errorf("kept an old file: %v", err)
+	}
+}
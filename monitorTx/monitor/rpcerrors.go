@@ -0,0 +1,170 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Classes of the RPC errors tallied by RPCErrors.
+const (
+	ErrClassTimeout    = "timeout"
+	ErrClassRateLimit  = "rate-limit"
+	ErrClassNotFound   = "not-found"
+	ErrClassConnection = "connection"
+	ErrClassOther      = "other"
+)
+
+// Calls of the RPC errors tallied by RPCErrors.
+const (
+	CallFetch = "fetch"
+	CallSend  = "send"
+	CallRelay = "relay"
+)
+
+// ClassifyError returns the class of the error of an RPC call.
+func ClassifyError(err error) string {
+	if IsRateLimited(err) {
+		return ErrClassRateLimit
+	}
+	if errors.Is(err, ethereum.NotFound) {
+		return ErrClassNotFound
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrClassTimeout
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, rpc.ErrClientQuit) {
+		return ErrClassConnection
+	}
+
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "timeout"):
+		return ErrClassTimeout
+	case strings.Contains(msg, "connection"):
+		return ErrClassConnection
+	}
+	return ErrClassOther
+}
+
+// RPCErrorCount is how often a call failed with errors of a class.
+type RPCErrorCount struct {
+	Call  string
+	Class string
+	Count int
+}
+
+type rpcErrorKey struct{ call, class string }
+
+// rpcErrorTally counts RPC errors by call and class.
+type rpcErrorTally struct {
+	mu     sync.Mutex
+	counts map[rpcErrorKey]int
+}
+
+// rpcErrs tallies the errors of every Monitor and Responder, like the
+// metrics do.
+var rpcErrs rpcErrorTally
+
+func (t *rpcErrorTally) observe(call string, err error) {
+	// cancelled calls are ours, not the provider's
+	if err == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	class := ClassifyError(err)
+	rpcErrors.WithLabelValues(call, class).Inc()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.counts == nil {
+		t.counts = make(map[rpcErrorKey]int)
+	}
+	t.counts[rpcErrorKey{call, class}]++
+}
+
+// list returns the counts, most frequent first.
+func (t *rpcErrorTally) list() []RPCErrorCount {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make([]RPCErrorCount, 0, len(t.counts))
+	for k, n := range t.counts {
+		out = append(out, RPCErrorCount{Call: k.call, Class: k.class, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Call != b.Call {
+			return a.Call < b.Call
+		}
+		return a.Class < b.Class
+	})
+	return out
+}
+
+// observeRPCError counts a failed call, it ignores a nil err.
+func observeRPCError(call string, err error) {
+	rpcErrs.observe(call, err)
+}
+
+// RPCErrors returns the RPC errors of the process so far by call and
+// class, most frequent first.
+func RPCErrors() []RPCErrorCount {
+	return rpcErrs.list()
+}
+
+// WriteRPCErrors writes errs as a table.
+func WriteRPCErrors(w io.Writer, errs []RPCErrorCount) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CALL\tCLASS\tERRORS")
+	for _, e := range errs {
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", e.Call, e.Class, e.Count)
+	}
+	return tw.Flush()
+}
+
+// logRPCErrors logs the report of WriteRPCErrors.
+func logRPCErrors() {
+	errs := RPCErrors()
+	if len(errs) == 0 {
+		slog.Info("No RPC errors")
+		return
+	}
+	var buf bytes.Buffer
+	WriteRPCErrors(&buf, errs)
+	slog.Info("RPC errors by type\n" + buf.String())
+}
+
+// logRPCErrorsEvery logs the RPC errors every interval until ctx is done.
+func logRPCErrorsEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			logRPCErrors()
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{context.DeadlineExceeded, ErrClassTimeout},
+		{errors.New("i/o timeout"), ErrClassTimeout},
+		{errors.New("429 Too Many Requests"), ErrClassRateLimit},
+		{fmt.Errorf("fetch: %w", ethereum.NotFound), ErrClassNotFound},
+		{syscall.ECONNREFUSED, ErrClassConnection},
+		{errors.New("use of closed network connection"), ErrClassConnection},
+		{errors.New("nonce too low"), ErrClassOther},
+	}
+	for _, tt := range tests {
+		if got := ClassifyError(tt.err); got != tt.want {
+			t.Errorf("%v: got %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRPCErrorTally(t *testing.T) {
+	var tally rpcErrorTally
+	tally.observe(CallFetch, ethereum.NotFound)
+	tally.observe(CallFetch, context.DeadlineExceeded)
+	tally.observe(CallFetch, ethereum.NotFound)
+	tally.observe(CallSend, syscall.ECONNRESET)
+	tally.observe(CallFetch, context.Canceled)
+
+	want := []RPCErrorCount{
+		{CallFetch, ErrClassNotFound, 2},
+		{CallFetch, ErrClassTimeout, 1},
+		{CallSend, ErrClassConnection, 1},
+	}
+	got := tally.list()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,137 @@
+package monitor
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultSeenLimit is the number of hashes a SeenStore remembers.
+const DefaultSeenLimit = 10000
+
+// SeenStore remembers processed tx hashes across restarts in an append-only
+// file of one hash per line. Only the latest limit hashes are kept, the file
+// is compacted once it holds twice as many.
+type SeenStore struct {
+	mu    sync.Mutex
+	path  string
+	limit int
+	file  *os.File
+	lines int
+	order []common.Hash
+	set   map[common.Hash]struct{}
+}
+
+// OpenSeenStore loads the hashes recorded in path, creating it if needed.
+func OpenSeenStore(path string, limit int) (*SeenStore, error) {
+	if limit <= 0 {
+		limit = DefaultSeenLimit
+	}
+	s := &SeenStore{path: path, limit: limit, set: make(map[common.Hash]struct{})}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	s.file = f
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		h, err := HexStringToTxHash(scanner.Text())
+		if err != nil {
+			continue
+		}
+		s.lines++
+		s.remember(h)
+	}
+	if err := scanner.Err(); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Seen reports whether h has been recorded.
+func (s *SeenStore) Seen(h common.Hash) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.set[h]
+	return ok
+}
+
+// Add records h.
+func (s *SeenStore) Add(h common.Hash) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.set[h]; ok {
+		return nil
+	}
+	if _, err := fmt.Fprintf(s.file, "0x%x\n", h); err != nil {
+		return err
+	}
+	s.lines++
+	s.remember(h)
+
+	if s.lines >= 2*s.limit {
+		return s.compact()
+	}
+	return nil
+}
+
+// Close closes the underlying file.
+func (s *SeenStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// remember adds h, evicting the oldest hash beyond limit.
+func (s *SeenStore) remember(h common.Hash) {
+	if _, ok := s.set[h]; ok {
+		return
+	}
+	s.set[h] = struct{}{}
+	s.order = append(s.order, h)
+
+	if len(s.order) > s.limit {
+		delete(s.set, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+// compact rewrites the file with the remembered hashes only.
+func (s *SeenStore) compact() error {
+	tmp := s.path + ".tmp"
+
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	for _, h := range s.order {
+		fmt.Fprintf(w, "0x%x\n", h)
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+
+	s.file.Close()
+	if s.file, err = os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+		return err
+	}
+	s.lines = len(s.order)
+	return nil
+}
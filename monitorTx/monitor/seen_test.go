@@ -0,0 +1,38 @@
+package monitor
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestSeenStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seen")
+
+	s, err := OpenSeenStore(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 7; i++ {
+		if err := s.Add(common.BigToHash(big.NewInt(int64(i)))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.Close()
+
+	// reopening keeps only the latest three
+	s, err = OpenSeenStore(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	for i := 1; i <= 7; i++ {
+		want := i > 4
+		if got := s.Seen(common.BigToHash(big.NewInt(int64(i)))); got != want {
+			t.Errorf("hash %d: seen %v, want %v", i, got, want)
+		}
+	}
+}
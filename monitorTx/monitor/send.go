@@ -0,0 +1,85 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Defaults of the Responder send retries.
+const (
+	DefaultSendRetries = 3
+	DefaultSendBackoff = 500 * time.Millisecond
+)
+
+// send broadcasts tx, retrying transient failures as configured by
+// SendRetries and SendBackoff.
+func (r *Responder) send(ctx context.Context, client TxClient, tx *types.Transaction) error {
+	backoff := r.SendBackoff
+	if backoff <= 0 {
+		backoff = DefaultSendBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := client.SendTransaction(ctx, tx)
+		// a retry of a send that reached the node after all
+		if err == nil || (attempt > 1 && isKnownTx(err)) {
+			return nil
+		}
+		observeRPCError(CallSend, err)
+		if attempt > r.SendRetries || !isTransient(err) || ctx.Err() != nil {
+			return err
+		}
+
+		slog.Warn("Send tx failed, retrying", "hash", tx.Hash(), "attempt", attempt, "delay", backoff, "err", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// isTransient reports whether err is a failure to reach the node, or the
+// node being overloaded, that a later send may not hit. Rejections of the
+// tx itself, such as a bad nonce or insufficient funds, are permanent.
+func isTransient(err error) bool {
+	if isNonceError(err) || strings.Contains(strings.ToLower(err.Error()), "insufficient funds") {
+		return false
+	}
+	if IsRateLimited(err) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode >= 500 {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "connection reset") || strings.Contains(msg, "timeout")
+}
+
+// isKnownTx reports whether err is the node already holding the tx.
+func isKnownTx(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "already known") || strings.Contains(msg, "known transaction")
+}
@@ -0,0 +1,67 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("nonce too low"), false},
+		{errors.New("insufficient funds for gas * price + value"), false},
+		{errors.New("execution reverted"), false},
+		{context.DeadlineExceeded, true},
+		{rpc.HTTPError{StatusCode: 429}, true},
+		{rpc.HTTPError{StatusCode: 502}, true},
+		{rpc.HTTPError{StatusCode: 400}, false},
+		{fmt.Errorf("post: %w", syscall.ECONNRESET), true},
+		{errors.New("read tcp: connection reset by peer"), true},
+	}
+	for _, tt := range tests {
+		if got := isTransient(tt.err); got != tt.want {
+			t.Errorf("%v: got %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestProcessSendRetries(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	unavailable := rpc.HTTPError{StatusCode: 503}
+
+	tests := []struct {
+		name    string
+		retries int
+		errs    []error
+		sent    int
+		wantErr bool
+	}{
+		{"transient then sent", 2, []error{unavailable, unavailable}, 1, false},
+		{"out of retries", 1, []error{unavailable, unavailable}, 0, true},
+		{"permanent", 3, []error{errors.New("insufficient funds")}, 0, true},
+		{"known after retry", 3, []error{unavailable, errors.New("already known")}, 0, false},
+	}
+
+	for _, tt := range tests {
+		client := newFakeClient()
+		client.sendErrs = tt.errs
+		r := &Responder{Key: key, To: &responseTo, SendRetries: tt.retries, SendBackoff: time.Millisecond}
+
+		err := r.Process(context.Background(), valueTx(0, nil), client)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: got %v", tt.name, err)
+		}
+		if len(client.sent) != tt.sent {
+			t.Errorf("%s: sent %d txs, want %d", tt.name, len(client.sent), tt.sent)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var ErrReverted = errors.New("response would revert")
+
+// simulate runs tx from from as an eth_call against the latest block,
+// failing with ErrReverted and the revert reason if the call fails.
+func simulate(ctx context.Context, client TxClient, from common.Address, tx *types.Transaction) error {
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+	if tx.Type() == types.LegacyTxType {
+		msg.GasPrice = tx.GasPrice()
+	} else {
+		msg.GasFeeCap, msg.GasTipCap = tx.GasFeeCap(), tx.GasTipCap()
+	}
+
+	if _, err := client.CallContract(ctx, msg, nil); err != nil {
+		reason := RevertReason(err)
+		slog.Warn("Simulated response reverts, not sending", "from", from, "to", tx.To(), "reason", reason)
+		return fmt.Errorf("%w: %s", ErrReverted, reason)
+	}
+	slog.Debug("Simulated response succeeds", "from", from, "to", tx.To())
+	return nil
+}
+
+// RevertReason is the Error(string) reason carried by the data of a failed
+// eth_call, or the error message when there is none.
+func RevertReason(err error) string {
+	var derr rpc.DataError
+	if !errors.As(err, &derr) {
+		return err.Error()
+	}
+	s, ok := derr.ErrorData().(string)
+	if !ok {
+		return err.Error()
+	}
+	data, decodeErr := hexutil.Decode(s)
+	if decodeErr != nil {
+		return err.Error()
+	}
+	if reason, unpackErr := abi.UnpackRevert(data); unpackErr == nil {
+		return reason
+	}
+	return fmt.Sprintf("%s (%s)", err, s)
+}
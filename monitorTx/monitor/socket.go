@@ -0,0 +1,175 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultSocketBuffer is the number of records queued for every reader of
+// a Socket.
+const DefaultSocketBuffer = 256
+
+// Socket streams matched txs as newline-delimited JSON TxRecords to every
+// reader connected to a Unix socket. A reader falling behind loses records
+// rather than holding up the matches, Dropped counts them.
+type Socket struct {
+	Path string
+
+	l       net.Listener
+	buffer  int
+	dropped atomic.Uint64
+
+	mu      sync.Mutex
+	readers map[*socketReader]struct{}
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+type socketReader struct {
+	conn  net.Conn
+	lines chan []byte
+	slow  bool
+}
+
+// ListenSocket listens on the Unix socket at path, replacing a stale socket
+// left by an earlier run, and queues up to buffer records per reader.
+func ListenSocket(path string, buffer int) (*Socket, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		os.Remove(path)
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if buffer <= 0 {
+		buffer = DefaultSocketBuffer
+	}
+
+	s := &Socket{Path: path, l: l, buffer: buffer, readers: make(map[*socketReader]struct{})}
+	s.wg.Add(1)
+	go s.accept()
+	return s, nil
+}
+
+// accept serves every connecting reader until the listener is closed.
+func (s *Socket) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.l.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				slog.Error("Socket accept failed", "socket", s.Path, "err", err)
+			}
+			return
+		}
+
+		r := &socketReader{conn: conn, lines: make(chan []byte, s.buffer)}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.readers[r] = struct{}{}
+		s.mu.Unlock()
+		slog.Debug("Socket reader connected", "socket", s.Path)
+
+		s.wg.Add(1)
+		go s.write(r)
+	}
+}
+
+// write copies the queued lines to r until it disconnects or the socket
+// is closed.
+func (s *Socket) write(r *socketReader) {
+	defer s.wg.Done()
+	defer r.conn.Close()
+
+	for line := range r.lines {
+		if _, err := r.conn.Write(line); err != nil {
+			slog.Debug("Socket reader disconnected", "socket", s.Path, "err", err)
+			s.remove(r)
+			for range r.lines {
+			}
+			return
+		}
+	}
+}
+
+// remove stops publishing to r and closes its queue.
+func (s *Socket) remove(r *socketReader) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.readers[r]; ok {
+		delete(s.readers, r)
+		close(r.lines)
+	}
+}
+
+// Handle makes s a Handler, it publishes the record of m.
+func (s *Socket) Handle(ctx context.Context, m Match) error {
+	return s.Publish(m.Record)
+}
+
+// Publish queues rec for every connected reader without waiting on any.
+func (s *Socket) Publish(rec *TxRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for r := range s.readers {
+		select {
+		case r.lines <- line:
+		default:
+			s.dropped.Add(1)
+			socketDropped.Inc()
+			if !r.slow {
+				r.slow = true
+				slog.Warn("Socket reader too slow, dropping records", "socket", s.Path, "buffer", s.buffer)
+			}
+		}
+	}
+	return nil
+}
+
+// Dropped returns the number of records lost to slow readers so far.
+func (s *Socket) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close writes out the queued records, giving up on readers not taking
+// them within a second, then disconnects them and removes the socket.
+func (s *Socket) Close() error {
+	err := s.l.Close()
+
+	s.mu.Lock()
+	s.closed = true
+	deadline := time.Now().Add(time.Second)
+	for r := range s.readers {
+		delete(s.readers, r)
+		close(r.lines)
+		r.conn.SetWriteDeadline(deadline)
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	if n := s.Dropped(); n > 0 {
+		slog.Warn("Socket readers missed records", "socket", s.Path, "dropped", n)
+	}
+	return err
+}
@@ -0,0 +1,126 @@
+package monitor
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// listenSocket listens on a fresh socket, its path kept short for the
+// socket path limit.
+func listenSocket(t *testing.T, buffer int) *Socket {
+	dir, err := os.MkdirTemp("", "sock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	s, err := ListenSocket(filepath.Join(dir, "monitor.sock"), buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// connect dials s and waits for it to count the reader among n.
+func connect(t *testing.T, s *Socket, n int) net.Conn {
+	conn, err := net.Dial("unix", s.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		s.mu.Lock()
+		got := len(s.readers)
+		s.mu.Unlock()
+		if got >= n {
+			return conn
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d readers connected, want %d", got, n)
+		}
+	}
+}
+
+func TestSocketPublish(t *testing.T) {
+	s := listenSocket(t, 0)
+	defer s.Close()
+
+	readers := []net.Conn{connect(t, s, 1), connect(t, s, 2)}
+
+	tx := valueTx(1, nil)
+	if err := s.Publish(NewTxRecord(tx, common.Address{1})); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, conn := range readers {
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		line, err := bufio.NewReader(conn).ReadBytes('\n')
+		if err != nil {
+			t.Fatalf("reader %d: %v", i, err)
+		}
+		var rec TxRecord
+		if err := json.Unmarshal(line, &rec); err != nil || rec.Hash != tx.Hash() {
+			t.Errorf("reader %d: got %s, %v", i, line, err)
+		}
+	}
+}
+
+func TestSocketSlowReader(t *testing.T) {
+	s := listenSocket(t, 1)
+	defer s.Close()
+
+	// a reader that never reads fills the socket and its queue
+	connect(t, s, 1)
+
+	rec := NewTxRecord(valueTx(1, make([]byte, 1024)), common.Address{1})
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 2000; i++ {
+			s.Publish(rec)
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Publish blocked on a slow reader")
+	}
+	if s.Dropped() == 0 {
+		t.Error("no record dropped")
+	}
+}
+
+func TestListenSocketStale(t *testing.T) {
+	s := listenSocket(t, 0)
+	path := s.Path
+	s.Close()
+
+	// a socket file left behind by a crash is replaced
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+
+	s, err = ListenSocket(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	file := filepath.Join(filepath.Dir(path), "file")
+	os.WriteFile(file, nil, 0644)
+	if _, err := ListenSocket(file, 0); err == nil {
+		t.Error("regular file replaced")
+	}
+}
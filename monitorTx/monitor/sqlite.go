@@ -0,0 +1,175 @@
+package monitor
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const (
+	// DefaultSQLiteBatch is the number of txs inserted per transaction.
+	DefaultSQLiteBatch = 100
+
+	// sqliteFlushInterval bounds how long a tx waits in a partial batch.
+	sqliteFlushInterval = time.Second
+)
+
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS transactions (
+	hash        TEXT PRIMARY KEY,
+	from_addr   TEXT NOT NULL,
+	to_addr     TEXT,
+	value       TEXT NOT NULL,
+	gas         INTEGER NOT NULL,
+	gas_price   TEXT,
+	nonce       INTEGER NOT NULL,
+	input       BLOB,
+	received_at INTEGER NOT NULL
+)`
+
+// a hash recorded before a restart is ignored, not duplicated
+const sqliteInsert = `INSERT OR IGNORE INTO transactions
+	(hash, from_addr, to_addr, value, gas, gas_price, nonce, input, received_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+type sqliteRow struct {
+	rec *TxRecord
+	at  time.Time
+}
+
+// SQLiteStore records matched txs in the transactions table of a SQLite
+// database. Txs are inserted in batches, flushed once full or after
+// sqliteFlushInterval.
+type SQLiteStore struct {
+	db    *sql.DB
+	batch int
+
+	mu      sync.Mutex
+	pending []sqliteRow
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// OpenSQLiteStore opens the database at path, creating the table if needed.
+func OpenSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	s := &SQLiteStore{db: db, batch: DefaultSQLiteBatch, done: make(chan struct{})}
+	s.wg.Add(1)
+	go s.flushLoop()
+	return s, nil
+}
+
+// Add queues rec, received at at, for insertion.
+func (s *SQLiteStore) Add(rec *TxRecord, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending = append(s.pending, sqliteRow{rec, at})
+	if len(s.pending) >= s.batch {
+		return s.flush()
+	}
+	return nil
+}
+
+// Flush inserts the queued txs.
+func (s *SQLiteStore) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flush()
+}
+
+// Close flushes the queued txs and closes the database.
+func (s *SQLiteStore) Close() error {
+	close(s.done)
+	s.wg.Wait()
+
+	err := s.Flush()
+	if cerr := s.db.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (s *SQLiteStore) flushLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(sqliteFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if err := s.Flush(); err != nil {
+				slog.Error("Flush sqlite", "err", err)
+			}
+		}
+	}
+}
+
+// flush inserts the queued txs in one transaction, s.mu must be held. The
+// batch is dropped if that fails, so that a bad row or a broken database
+// doesn't keep pending growing past the batch size.
+func (s *SQLiteStore) flush() error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+	err := s.insert()
+	if err != nil {
+		err = fmt.Errorf("dropped %d txs: %w", len(s.pending), err)
+	}
+	s.pending = s.pending[:0]
+	return err
+}
+
+// insert inserts the queued txs in one transaction.
+func (s *SQLiteStore) insert() error {
+	dbtx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := dbtx.Prepare(sqliteInsert)
+	if err != nil {
+		dbtx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, row := range s.pending {
+		rec := row.rec
+
+		var to, price interface{}
+		if rec.To != nil {
+			to = rec.To.Hex()
+		}
+		if rec.GasPrice != nil {
+			price = rec.GasPrice.String()
+		}
+
+		_, err := stmt.Exec(rec.Hash.Hex(), rec.From.Hex(), to, rec.Value.String(),
+			rec.Gas, price, rec.Nonce, []byte(rec.Input), row.at.UnixMilli())
+		if err != nil {
+			dbtx.Rollback()
+			return err
+		}
+	}
+
+	if err := dbtx.Commit(); err != nil {
+		dbtx.Rollback()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package monitor
+
+import (
+	"database/sql"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestSQLiteStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "txs.db")
+	to := common.HexToAddress("0x003be5Df5FeF651EF0C59cD175c73ca1415f53eA")
+	at := time.UnixMilli(1700000000000)
+
+	recs := []*TxRecord{
+		{Hash: common.Hash{1}, To: &to, Value: big.NewInt(1000), Gas: 21000, GasPrice: big.NewInt(7), Nonce: 1},
+		{Hash: common.Hash{2}, Value: big.NewInt(0), Gas: 53000, Nonce: 2, Input: []byte{0x60, 0x80}},
+	}
+
+	s, err := OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.batch = 2
+	for _, rec := range append(recs, recs[0]) {
+		if err := s.Add(rec, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a restart recording the same tx again
+	if s, err = OpenSQLiteStore(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(recs[1], at); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM transactions").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("got %d rows, want 2", count)
+	}
+
+	var toAddr sql.NullString
+	var value string
+	var receivedAt int64
+	err = db.QueryRow("SELECT to_addr, value, received_at FROM transactions WHERE hash = ?", recs[0].Hash.Hex()).
+		Scan(&toAddr, &value, &receivedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if toAddr.String != to.Hex() || value != "1000" || receivedAt != at.UnixMilli() {
+		t.Errorf("got to %v value %s received %d", toAddr, value, receivedAt)
+	}
+}
+
+func TestSQLiteStoreDropsFailedBatch(t *testing.T) {
+	s, err := OpenSQLiteStore(filepath.Join(t.TempDir(), "txs.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.batch = 2
+	s.db.Close()
+
+	rec := &TxRecord{Hash: common.Hash{1}, Value: big.NewInt(0)}
+	for i := 0; i < 3; i++ {
+		err := s.Add(rec, time.Now())
+		if i%2 == 1 && err == nil {
+			t.Errorf("add %d didn't fail on a closed database", i)
+		}
+	}
+	if len(s.pending) != 1 {
+		t.Errorf("%d txs pending, want 1 after the failed batch was dropped", len(s.pending))
+	}
+	if err := s.Close(); err == nil {
+		t.Error("close flushed into a closed database")
+	}
+	if len(s.pending) != 0 {
+		t.Errorf("%d txs pending after close", len(s.pending))
+	}
+}
@@ -0,0 +1,93 @@
+package monitor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Stats sums up the matched txs of a watched address.
+type Stats struct {
+	Matches int
+	Value   *big.Int
+	LastTx  common.Hash
+}
+
+// recordMatch adds tx to the stats of watched.
+func (m *Monitor) recordMatch(watched common.Address, tx *types.Transaction) {
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+
+	if m.stats == nil {
+		m.stats = make(map[common.Address]*Stats)
+	}
+	s, ok := m.stats[watched]
+	if !ok {
+		s = &Stats{Value: new(big.Int)}
+		m.stats[watched] = s
+	}
+	s.Matches++
+	s.Value.Add(s.Value, tx.Value())
+	s.LastTx = tx.Hash()
+}
+
+// Stats returns a copy of the stats of every watched address, including
+// those without matches.
+func (m *Monitor) Stats() map[common.Address]Stats {
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+
+	out := make(map[common.Address]Stats)
+	for _, set := range []map[common.Address]struct{}{m.Senders, m.Recipients} {
+		for addr := range set {
+			out[addr] = Stats{Value: new(big.Int)}
+		}
+	}
+	for addr, s := range m.stats {
+		out[addr] = Stats{Matches: s.Matches, Value: new(big.Int).Set(s.Value), LastTx: s.LastTx}
+	}
+	return out
+}
+
+// WriteStats writes the stats as a table, busiest address first.
+func (m *Monitor) WriteStats(w io.Writer) error {
+	stats := m.Stats()
+
+	addrs := make([]common.Address, 0, len(stats))
+	for addr := range stats {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		a, b := stats[addrs[i]], stats[addrs[j]]
+		if a.Matches != b.Matches {
+			return a.Matches > b.Matches
+		}
+		return bytes.Compare(addrs[i][:], addrs[j][:]) < 0
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ADDRESS\tMATCHES\tVALUE (ETH)\tLAST TX")
+	for _, addr := range addrs {
+		s := stats[addr]
+		last := "-"
+		if s.Matches > 0 {
+			last = s.LastTx.Hex()
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", addr.Hex(), s.Matches, FormatEther(s.Value), last)
+	}
+	return tw.Flush()
+}
+
+// logStats logs the session report of WriteStats.
+func (m *Monitor) logStats() {
+	var buf bytes.Buffer
+	m.WriteStats(&buf)
+	slog.Info("Matches per watched address\n" + buf.String())
+}
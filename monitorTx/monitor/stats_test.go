@@ -0,0 +1,39 @@
+package monitor
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestStats(t *testing.T) {
+	busy := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	idle := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	m := &Monitor{
+		Senders:    AddressSet([]common.Address{busy}),
+		Recipients: AddressSet([]common.Address{idle}),
+	}
+
+	first, last := valueTx(1e18, nil), valueTx(5e17, []byte{1})
+	m.recordMatch(busy, first)
+	m.recordMatch(busy, last)
+
+	stats := m.Stats()
+	if s := stats[busy]; s.Matches != 2 || FormatEther(s.Value) != "1.5" || s.LastTx != last.Hash() {
+		t.Errorf("busy: got %+v", s)
+	}
+	if s, ok := stats[idle]; !ok || s.Matches != 0 || s.Value.Sign() != 0 {
+		t.Errorf("idle: got %+v, %v", s, ok)
+	}
+
+	var buf bytes.Buffer
+	if err := m.WriteStats(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], busy.Hex()) || !strings.HasPrefix(lines[2], idle.Hex()) {
+		t.Errorf("unexpected table:\n%s", buf.String())
+	}
+}
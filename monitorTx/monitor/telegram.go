@@ -0,0 +1,126 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTelegramTimeout = 5 * time.Second
+
+	telegramAPI = "https://api.telegram.org"
+
+	// telegramBatchLines caps the txs listed in the message of a batch,
+	// messages are limited to 4096 characters.
+	telegramBatchLines = 20
+)
+
+// Telegram sends a message about every matched tx to a chat through a bot.
+type Telegram struct {
+	Token  string
+	ChatID string
+
+	client *http.Client
+}
+
+func NewTelegram(token, chatID string, timeout time.Duration) *Telegram {
+	return &Telegram{
+		Token:  token,
+		ChatID: chatID,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Handle makes t a Handler, it notifies the record of m.
+func (t *Telegram) Handle(ctx context.Context, m Match) error {
+	return t.Notify(ctx, m.Record)
+}
+
+// HandleBatch makes t a BatchHandler, it sends one message listing the
+// records of ms.
+func (t *Telegram) HandleBatch(ctx context.Context, ms []Match) error {
+	if len(ms) == 1 {
+		return t.Notify(ctx, ms[0].Record)
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "%d matched txs", len(ms))
+	for i, m := range ms {
+		if i == telegramBatchLines {
+			fmt.Fprintf(&text, "\nand %d more", len(ms)-i)
+			break
+		}
+		rec := m.Record
+		fmt.Fprintf(&text, "\n%s: %s -> %s, %s ETH%s", rec.Hash.Hex(), rec.From.Hex(), recipient(rec), FormatEther(rec.Value), worth(rec))
+	}
+	return t.send(ctx, text.String())
+}
+
+// recipient describes the to of rec.
+func recipient(rec *TxRecord) string {
+	if rec.To == nil {
+		return "contract creation"
+	}
+	return rec.To.Hex()
+}
+
+// worth describes the USD value of rec, if known.
+func worth(rec *TxRecord) string {
+	if rec.ValueUSD == nil {
+		return ""
+	}
+	return fmt.Sprintf(" (~$%.2f)", *rec.ValueUSD)
+}
+
+// HandleBalance makes t a BalanceHandler, it sends a message describing c.
+func (t *Telegram) HandleBalance(ctx context.Context, c BalanceChange) error {
+	return t.send(ctx, fmt.Sprintf("Balance changed %s\nblock: %v\nold: %s ETH\nnew: %s ETH\ndelta: %s ETH",
+		c.Address.Hex(), c.Block, FormatEther(c.Old), FormatEther(c.New), FormatEther(c.Delta())))
+}
+
+// Notify sends a message describing rec.
+func (t *Telegram) Notify(ctx context.Context, rec *TxRecord) error {
+	return t.send(ctx, fmt.Sprintf("Matched tx %s\nfrom: %s\nto: %s\nvalue: %s ETH%s",
+		rec.Hash.Hex(), rec.From.Hex(), recipient(rec), FormatEther(rec.Value), worth(rec)))
+}
+
+// send posts text to the chat, giving up once ctx is done.
+func (t *Telegram) send(ctx context.Context, text string) error {
+	form := url.Values{
+		"chat_id": {t.ChatID},
+		"text":    {text},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, telegramAPI+"/bot"+t.Token+"/sendMessage", strings.NewReader(form.Encode()))
+	if err != nil {
+		return errors.New("telegram: invalid request")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		// the url holds the token, keep it out of the logs
+		if uerr, ok := err.(*url.Error); ok {
+			return uerr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("telegram: %s", resp.Status)
+	}
+	if !result.OK {
+		return fmt.Errorf("telegram: %s", result.Description)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// txTypeNames names the tx types accepted by ParseTxTypes.
+var txTypeNames = map[string]uint8{
+	"legacy":     types.LegacyTxType,
+	"accesslist": types.AccessListTxType,
+	"dynamic":    types.DynamicFeeTxType,
+	"blob":       types.BlobTxType,
+	"setcode":    types.SetCodeTxType,
+}
+
+// ParseTxTypes converts tx type names, legacy, accesslist, dynamic, blob or
+// setcode, to a type set.
+func ParseTxTypes(list []string) (map[uint8]struct{}, error) {
+	set := make(map[uint8]struct{}, len(list))
+	var invalid []string
+
+	for _, s := range list {
+		t, ok := txTypeNames[strings.ToLower(strings.TrimSpace(s))]
+		if !ok {
+			invalid = append(invalid, s)
+			continue
+		}
+		set[t] = struct{}{}
+	}
+
+	if len(invalid) > 0 {
+		names := make([]string, 0, len(txTypeNames))
+		for name := range txTypeNames {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("invalid tx type: %s, want one of %s", strings.Join(invalid, ", "), strings.Join(names, ", "))
+	}
+	return set, nil
+}
+
+// TypeFilter passes txs of one of txTypes, see ParseTxTypes.
+func TypeFilter(txTypes map[uint8]struct{}) Filter {
+	return func(tx *types.Transaction) bool {
+		_, ok := txTypes[tx.Type()]
+		return ok
+	}
+}
+
+// blobCheckAfter pending txs without a blob tx among them suggest that the
+// node doesn't announce blob txs.
+const blobCheckAfter = 5000
+
+// blobCheck warns once if no blob tx shows up among the first
+// blobCheckAfter fetched txs. Nodes keeping blob txs in a pool of their own
+// may leave them out of the newPendingTransactions subscription.
+type blobCheck struct {
+	seen  atomic.Uint64
+	blobs atomic.Uint64
+}
+
+// observe counts tx and reports whether it makes blobCheckAfter txs
+// without a blob tx, which is when to warn.
+func (c *blobCheck) observe(tx *types.Transaction) bool {
+	if tx.Type() == types.BlobTxType {
+		c.blobs.Add(1)
+	}
+	return c.seen.Add(1) == blobCheckAfter && c.blobs.Load() == 0
+}
@@ -0,0 +1,68 @@
+package monitor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+)
+
+func TestTypeFilter(t *testing.T) {
+	to := common.Address{1}
+	chainID := big.NewInt(1)
+	txs := map[string]*types.Transaction{
+		"legacy":     types.NewTx(&types.LegacyTx{To: &to, Gas: 21000, GasPrice: big.NewInt(1)}),
+		"accesslist": types.NewTx(&types.AccessListTx{ChainID: chainID, To: &to, Gas: 21000, GasPrice: big.NewInt(1)}),
+		"dynamic":    types.NewTx(&types.DynamicFeeTx{ChainID: chainID, To: &to, Gas: 21000, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(1)}),
+		"blob": types.NewTx(&types.BlobTx{ChainID: uint256.NewInt(1), To: to, Gas: 21000, GasTipCap: uint256.NewInt(1), GasFeeCap: uint256.NewInt(1),
+			BlobFeeCap: uint256.NewInt(1), BlobHashes: []common.Hash{{1}}}),
+		"setcode": types.NewTx(&types.SetCodeTx{ChainID: uint256.NewInt(1), To: to, Gas: 21000, GasTipCap: uint256.NewInt(1), GasFeeCap: uint256.NewInt(1)}),
+	}
+
+	for name := range txs {
+		set, err := ParseTxTypes([]string{name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		filter := TypeFilter(set)
+		for other, tx := range txs {
+			if got := filter(tx); got != (other == name) {
+				t.Errorf("filter %s passed %s: %v", name, other, got)
+			}
+		}
+	}
+
+	set, err := ParseTxTypes([]string{"Legacy", " blob"})
+	if err != nil || len(set) != 2 {
+		t.Errorf("parsed %v, %v", set, err)
+	}
+	if _, err := ParseTxTypes([]string{"blob", "eip4844"}); err == nil {
+		t.Error("parsed an unknown tx type")
+	}
+}
+
+func TestBlobCheck(t *testing.T) {
+	legacy := valueTx(0, nil)
+	blob := types.NewTx(&types.BlobTx{ChainID: uint256.NewInt(1), Gas: 21000, BlobHashes: []common.Hash{{1}}})
+
+	var quiet blobCheck
+	warned := 0
+	for i := 0; i < 2*blobCheckAfter; i++ {
+		if quiet.observe(legacy) {
+			warned++
+		}
+	}
+	if warned != 1 {
+		t.Errorf("warned %d times without blob txs, want once", warned)
+	}
+
+	var busy blobCheck
+	busy.observe(blob)
+	for i := 1; i < blobCheckAfter; i++ {
+		if busy.observe(legacy) {
+			t.Fatal("warned after a blob tx")
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"text/template"
+	"time"
+)
+
+const (
+	DefaultWebhookTimeout = 5 * time.Second
+	DefaultWebhookRetries = 2
+)
+
+// SignatureHeader carries the HMAC of a webhook body, see Webhook.Secret.
+const SignatureHeader = "X-Signature"
+
+// Webhook posts matched txs as JSON TxRecords to a URL.
+type Webhook struct {
+	URL string
+
+	// Retries is the number of extra attempts after a failed post.
+	Retries int
+
+	// Header is sent with every post, such as an API key.
+	Header http.Header
+
+	// Template, if set, wraps the record in an envelope, see
+	// ParseWebhookTemplate.
+	Template *template.Template
+
+	// Secret, if set, signs every body with HMAC-SHA256 in the
+	// SignatureHeader as "sha256=" and the hex digest.
+	Secret []byte
+
+	client *http.Client
+}
+
+// webhookData is what a webhook template is executed with.
+type webhookData struct {
+	// Record is the JSON of the TxRecord, Hash its hash.
+	Record string
+	Hash   string
+}
+
+// ParseWebhookTemplate parses the template of a webhook body in which
+// {{.Record}} stands for the JSON record and {{.Hash}} for the tx hash,
+// such as {"method": "notify", "params": [{{.Record}}]}. It fails unless
+// the template gives valid JSON.
+func ParseWebhookTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("webhook").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("webhook template: %v", err)
+	}
+	if _, err := renderWebhook(tmpl, []byte(`{"hash":"0x00"}`), "0x00"); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// renderWebhook executes tmpl on the JSON record of the tx hash.
+func renderWebhook(tmpl *template.Template, record []byte, hash string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, webhookData{Record: string(record), Hash: hash}); err != nil {
+		return nil, fmt.Errorf("webhook template: %v", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, errors.New("webhook template does not give valid JSON")
+	}
+	return buf.Bytes(), nil
+}
+
+// Sign returns the SignatureHeader value of body under secret.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func NewWebhook(url string, timeout time.Duration, retries int) *Webhook {
+	return &Webhook{
+		URL:     url,
+		Retries: retries,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Handle makes w a Handler, it notifies the record of m.
+func (w *Webhook) Handle(ctx context.Context, m Match) error {
+	return w.Notify(ctx, m.Record)
+}
+
+// HandleBalance makes w a BalanceHandler, it posts c as JSON, in the
+// envelope of Template as the record with an empty hash.
+func (w *Webhook) HandleBalance(ctx context.Context, c BalanceChange) error {
+	body, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return w.notify(ctx, body, "", "address", c.Address)
+}
+
+// Notify posts rec, retrying on transport errors and non-2xx responses
+// until ctx is done.
+func (w *Webhook) Notify(ctx context.Context, rec *TxRecord) error {
+	body, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return w.notify(ctx, body, rec.Hash.Hex(), "hash", rec.Hash)
+}
+
+// notify posts the JSON record, wrapped by Template, attrs naming it in
+// the log.
+func (w *Webhook) notify(ctx context.Context, record []byte, hash string, attrs ...any) error {
+	body := record
+	if w.Template != nil {
+		var err error
+		if body, err = renderWebhook(w.Template, record, hash); err != nil {
+			return err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := w.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		slog.Warn("Webhook failed", append(attrs, "attempt", attempt+1, "err", err)...)
+
+		if attempt >= w.Retries || ctx.Err() != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt+1) * time.Second):
+		}
+	}
+}
+
+func (w *Webhook) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, values := range w.Header {
+		req.Header[name] = values
+	}
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if len(w.Secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(w.Secret, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
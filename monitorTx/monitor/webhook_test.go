@@ -0,0 +1,125 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestWebhookEnvelope(t *testing.T) {
+	type request struct {
+		header http.Header
+		body   []byte
+	}
+	got := make(chan request, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- request{r.Header, body}
+	}))
+	defer ts.Close()
+
+	tmpl, err := ParseWebhookTemplate(`{"jsonrpc": "2.0", "method": "tx_matched", "params": [{{.Record}}], "id": "{{.Hash}}"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewWebhook(ts.URL, DefaultWebhookTimeout, 0)
+	w.Header = http.Header{"X-Api-Key": {"k1"}}
+	w.Template = tmpl
+	w.Secret = []byte("shared")
+
+	rec := NewTxRecord(valueTx(1, nil), common.Address{1})
+	if err := w.Notify(context.Background(), rec); err != nil {
+		t.Fatal(err)
+	}
+	req := <-got
+
+	if key := req.header.Get("X-Api-Key"); key != "k1" {
+		t.Errorf("api key %q, want k1", key)
+	}
+	if sig, want := req.header.Get(SignatureHeader), Sign([]byte("shared"), req.body); sig != want {
+		t.Errorf("signature %q, want %q", sig, want)
+	}
+
+	var envelope struct {
+		Method string     `json:"method"`
+		Params []TxRecord `json:"params"`
+		ID     string     `json:"id"`
+	}
+	if err := json.Unmarshal(req.body, &envelope); err != nil {
+		t.Fatalf("%s: %v", req.body, err)
+	}
+	if envelope.Method != "tx_matched" || len(envelope.Params) != 1 || envelope.Params[0].Hash != rec.Hash || envelope.ID != rec.Hash.Hex() {
+		t.Errorf("posted %s", req.body)
+	}
+}
+
+func TestParseWebhookTemplate(t *testing.T) {
+	for _, text := range []string{
+		`{"record": "{{.Record}}"}`,
+		`{"record": {{.Record}}`,
+		`{"record": {{.Unknown}}}`,
+		`{{.Record`,
+	} {
+		if _, err := ParseWebhookTemplate(text); err == nil {
+			t.Errorf("%s accepted", text)
+		}
+	}
+}
+
+func TestSign(t *testing.T) {
+	// RFC 4231 test case 2
+	want := "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	if got := Sign([]byte("Jefe"), []byte("what do ya want for nothing?")); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestWebhookCancel(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	// the retries would wait a second and more, a done ctx cuts them short
+	w := NewWebhook(ts.URL, DefaultWebhookTimeout, 5)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := w.Notify(ctx, NewTxRecord(valueTx(1, nil), common.Address{1})); err == nil {
+		t.Fatal("failing webhook succeeded")
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("gave up after %v, want the ctx deadline", d)
+	}
+}
+
+func TestWebhookBalance(t *testing.T) {
+	got := make(chan []byte, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- body
+	}))
+	defer ts.Close()
+
+	var h Handler = NewWebhook(ts.URL, DefaultWebhookTimeout, 0)
+	c := BalanceChange{Address: common.Address{1}, Block: big.NewInt(7), Old: big.NewInt(5), New: big.NewInt(2)}
+	if err := HandleBalance(context.Background(), h, c); err != nil {
+		t.Fatal(err)
+	}
+
+	var posted BalanceChange
+	if err := json.Unmarshal(<-got, &posted); err != nil {
+		t.Fatal(err)
+	}
+	if posted.Address != c.Address || posted.New.Cmp(c.New) != 0 {
+		t.Errorf("posted %+v, want %+v", posted, c)
+	}
+}